@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ruslanbes/kubrai/property"
+	"github.com/ruslanbes/kubrai/solver"
+)
+
+// association file header. The version tells how the lines are written:
+//  1. key:val,val lines, split on the separators as they are
+//  2. a backslash makes the next character literal, lines starting with # are comments
+//
+// Files without the header are of version 1. Blank lines are skipped in every version
+const (
+	assocHeader  = "# kubrai associations v"
+	assocVersion = 2
+)
+
+const (
+	assocComment = "#"
+	assocEscape  = '\\'
+)
+
+// why an association line can't be read
+const (
+	reasonNoKeySep  = "missing key separator"
+	reasonBadEscape = "unfinished escape"
+)
+
+// readAssoc reads the association file. A line that can't be read is an assocLineError
+func readAssoc(assocFile string) (map[string][]string, error) {
+	f, err := os.Open(assocFile)
+	if err != nil {
+		return map[string][]string{}, err
+	}
+	defer f.Close()
+
+	assoc := make(map[string][]string)
+
+	version := 1
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if line == 1 && strings.HasPrefix(text, assocHeader) {
+			version, err = strconv.Atoi(strings.TrimPrefix(text, assocHeader))
+			if err != nil || version < 1 || version > assocVersion {
+				return map[string][]string{}, &assocVersionError{assocFile, text}
+			}
+			continue
+		}
+		if strings.TrimSpace(text) == "" || version > 1 && strings.HasPrefix(text, assocComment) {
+			continue
+		}
+
+		key, vals, reason := parseAssocLine(text, version > 1)
+		if reason != "" {
+			return map[string][]string{}, &assocLineError{assocFile, line, text, reason}
+		}
+		assoc[key] = vals
+	}
+
+	if err := scanner.Err(); err != nil {
+		return map[string][]string{}, err
+	}
+
+	return assoc, nil
+}
+
+// parseAssocLine splits the line into the key and its values. With escapes on, a backslash
+// makes the next character literal. The reason tells why the line can't be read
+func parseAssocLine(line string, escapes bool) (string, []string, string) {
+	keySep := property.AsString(propAssocFileKeySeparator)
+	valSep := property.AsString(propAssocFileValSeparator)
+	if !escapes {
+		keyVals := strings.SplitN(line, keySep, 2)
+		if len(keyVals) < 2 {
+			return "", []string{}, reasonNoKeySep
+		}
+		return keyVals[0], strings.Split(keyVals[1], valSep), ""
+	}
+
+	key := ""
+	keyFound := false
+	vals := []string{}
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == assocEscape:
+			if i+1 == len(line) {
+				return "", []string{}, reasonBadEscape
+			}
+			b.WriteByte(line[i+1])
+			i += 2
+		case !keyFound && keySep != "" && strings.HasPrefix(line[i:], keySep):
+			key, keyFound = b.String(), true
+			b.Reset()
+			i += len(keySep)
+		case keyFound && valSep != "" && strings.HasPrefix(line[i:], valSep):
+			vals = append(vals, b.String())
+			b.Reset()
+			i += len(valSep)
+		default:
+			b.WriteByte(line[i])
+			i++
+		}
+	}
+	if !keyFound {
+		return "", []string{}, reasonNoKeySep
+	}
+
+	return key, append(vals, b.String()), ""
+}
+
+// escapeAssoc puts a backslash before the backslashes and the separators of a key or a value
+func escapeAssoc(s string) string {
+	keySep := property.AsString(propAssocFileKeySeparator)
+	valSep := property.AsString(propAssocFileValSeparator)
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == assocEscape || keySep != "" && strings.HasPrefix(s[i:], keySep) || valSep != "" && strings.HasPrefix(s[i:], valSep) {
+			b.WriteByte(assocEscape)
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+// unescapeAssoc drops the escapes of a key or a value, false if it ends in the middle of an escape
+func unescapeAssoc(s string) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == assocEscape {
+			if i+1 == len(s) {
+				return "", false
+			}
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String(), true
+}
+
+// formatAssocLine writes the key and its values as a line of the current version
+func formatAssocLine(key string, vals []string) string {
+	escaped := make([]string, len(vals))
+	for i, v := range vals {
+		escaped[i] = escapeAssoc(v)
+	}
+
+	line := escapeAssoc(key) + property.AsString(propAssocFileKeySeparator) + strings.Join(escaped, property.AsString(propAssocFileValSeparator))
+	if strings.HasPrefix(line, assocComment) {
+		return string(assocEscape) + line
+	}
+	return line
+}
+
+// formatAssoc writes the associations in the canonical form: the header,
+// then a line per key in sorted order with the values in their order
+func formatAssoc(assoc map[string][]string) string {
+	var b strings.Builder
+	b.WriteString(assocHeader + strconv.Itoa(assocVersion) + "\n")
+	for _, k := range solver.MapStore(assoc).Keys() {
+		b.WriteString(formatAssocLine(k, assoc[k]) + "\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ruslanbes/kubrai/fileutils"
+)
+
+func Test_formatAssoc(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+	})
+
+	tests := []struct {
+		name  string
+		assoc map[string][]string
+		want  string
+	}{
+		{
+			name:  "Empty",
+			assoc: map[string][]string{},
+			want:  "# kubrai associations v2\n",
+		},
+		{
+			name:  "SortedKeys",
+			assoc: map[string][]string{"why": {"y"}, "policeman": {"cop", "bobby"}, "kid": {"bo"}},
+			want:  "# kubrai associations v2\nkid:bo\npoliceman:cop,bobby\nwhy:y\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatAssoc(tt.assoc); got != tt.want {
+				t.Errorf("formatAssoc() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_readAssoc_header(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+	})
+
+	assocFile := "./test/data/associationsHeader.test"
+	defer fileutils.FileRemove(assocFile)
+
+	tests := []struct {
+		name    string
+		content string
+		want    map[string][]string
+		wantErr error
+	}{
+		{
+			name:    "NoHeader",
+			content: "policeman:cop\n",
+			want:    map[string][]string{"policeman": {"cop"}},
+		},
+		{
+			name:    "Current",
+			content: "# kubrai associations v1\npoliceman:cop\n",
+			want:    map[string][]string{"policeman": {"cop"}},
+		},
+		{
+			name:    "Escapes",
+			content: "# kubrai associations v2\n# phone companies\n\nat\\&t:phone\\, maybe,\\#1\n\\#tag:tag\n",
+			want:    map[string][]string{"at&t": {"phone, maybe", "#1"}, "#tag": {"tag"}},
+		},
+		{
+			name:    "FirstVersionKeepsBackslashes",
+			content: "# kubrai associations v1\nat\\&t:phone\n\n",
+			want:    map[string][]string{"at\\&t": {"phone"}},
+		},
+		{
+			name:    "UnfinishedEscape",
+			content: "# kubrai associations v2\n# comment\npoliceman:cop\\\n",
+			want:    map[string][]string{},
+			wantErr: &assocLineError{assocFile, 3, "policeman:cop\\", reasonBadEscape},
+		},
+		{
+			name:    "CommentInFirstVersion",
+			content: "policeman:cop\n# comment\n",
+			want:    map[string][]string{},
+			wantErr: &assocLineError{assocFile, 2, "# comment", reasonNoKeySep},
+		},
+		{
+			name:    "Newer",
+			content: "# kubrai associations v9\npoliceman:cop\n",
+			want:    map[string][]string{},
+			wantErr: &assocVersionError{assocFile, "# kubrai associations v9"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileutils.FilePutContents(assocFile, tt.content)
+
+			got, err := readAssoc(assocFile)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("readAssoc() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readAssoc() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_readAssoc_malformed(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+	})
+
+	assocFile := "./test/data/associationsMalformed.test"
+	fileutils.FilePutContents(assocFile, "policeman:cop\nwhy y\n")
+	defer fileutils.FileRemove(assocFile)
+
+	_, err := readAssoc(assocFile)
+	want := &assocLineError{assocFile, 2, "why y", reasonNoKeySep}
+	if !reflect.DeepEqual(err, want) {
+		t.Errorf("readAssoc() error = %v, want %v", err, want)
+	}
+}
+
+func Test_parseAssocLine(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+	})
+
+	tests := []struct {
+		name    string
+		line    string
+		escapes bool
+		want    string
+		want1   []string
+		want2   string
+	}{
+		{
+			name:    "Plain",
+			line:    "policeman:cop,bobby",
+			escapes: true,
+			want:    "policeman",
+			want1:   []string{"cop", "bobby"},
+		},
+		{
+			name:    "EscapedSeparators",
+			line:    `time\:out:a\,b,c\\`,
+			escapes: true,
+			want:    "time:out",
+			want1:   []string{"a,b", `c\`},
+		},
+		{
+			name:    "EmptyValue",
+			line:    "policeman:",
+			escapes: true,
+			want:    "policeman",
+			want1:   []string{""},
+		},
+		{
+			name:    "NoEscapes",
+			line:    `time\:out:a\,b`,
+			escapes: false,
+			want:    `time\`,
+			want1:   []string{`out:a\`, "b"},
+		},
+		{
+			name:    "NoKeySeparator",
+			line:    `policeman\:cop`,
+			escapes: true,
+			want:    "",
+			want1:   []string{},
+			want2:   reasonNoKeySep,
+		},
+		{
+			name:    "UnfinishedEscape",
+			line:    `policeman:cop\`,
+			escapes: true,
+			want:    "",
+			want1:   []string{},
+			want2:   reasonBadEscape,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1, got2 := parseAssocLine(tt.line, tt.escapes)
+			if got != tt.want {
+				t.Errorf("parseAssocLine() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("parseAssocLine() got1 = %v, want %v", got1, tt.want1)
+			}
+			if got2 != tt.want2 {
+				t.Errorf("parseAssocLine() got2 = %v, want %v", got2, tt.want2)
+			}
+		})
+	}
+}
+
+func Test_formatAssocLine(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: "::",
+		propAssocFileValSeparator: ",",
+	})
+
+	tests := []struct {
+		name string
+		key  string
+		vals []string
+		want string
+	}{
+		{
+			name: "Plain",
+			key:  "policeman",
+			vals: []string{"cop", "bobby"},
+			want: "policeman::cop,bobby",
+		},
+		{
+			name: "Separators",
+			key:  "a::b",
+			vals: []string{"c,d", `e\`},
+			want: `a\::b::c\,d,e\\`,
+		},
+		{
+			name: "Comment",
+			key:  "#tag",
+			vals: []string{"tag"},
+			want: `\#tag::tag`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatAssocLine(tt.key, tt.vals)
+			if got != tt.want {
+				t.Errorf("formatAssocLine() = %v, want %v", got, tt.want)
+			}
+
+			key, vals, reason := parseAssocLine(got, true)
+			if reason != "" || key != tt.key || !reflect.DeepEqual(vals, tt.vals) {
+				t.Errorf("parseAssocLine(formatAssocLine()) = %v, %v, %v, want %v, %v", key, vals, reason, tt.key, tt.vals)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ruslanbes/kubrai/solver"
+)
+
+// batch puzzle outcomes
+const (
+	batchSolved  = "solved"
+	batchFuzzy   = "fuzzy" // solved with edits where parts join
+	batchGuessed = "guessed"
+	batchFailed  = "failed"
+	batchWrong   = "wrong"
+)
+
+// batchComment starts a line of a batch file that is not a puzzle
+const batchComment = "#"
+
+// timeNow tells the time batch puzzles take
+var timeNow = time.Now
+
+// batchPuzzle is a line of a batch file
+type batchPuzzle struct {
+	kubraya  string
+	expected string // answer the puzzle should have, empty if not known
+}
+
+// parseBatchLine reads a kubraya and the optional expected answer after it
+func parseBatchLine(line string) (batchPuzzle, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || strings.HasPrefix(fields[0], batchComment) {
+		return batchPuzzle{}, false
+	}
+
+	return batchPuzzle{fields[0], strings.Join(fields[1:], " ")}, true
+}
+
+// solveBatchPuzzle solves the puzzle like solve with SolveAutoGuess on, without learning anything.
+// Returns the outcome and the best answer
+func solveBatchPuzzle(p batchPuzzle) (string, string, error) {
+	outcome := batchSolved
+	cands, err := solveCandidates(p.kubraya, solver.Constraint{})
+	if notFound(err) {
+		outcome = batchFuzzy
+		cands, err = fuzzySolveCandidates(p.kubraya, solver.Constraint{})
+	}
+	if notFound(err) {
+		outcome = batchGuessed
+		cands, err = guessCandidates(p.kubraya, solver.Constraint{})
+	}
+	if notFound(err) {
+		return batchFailed, "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	if p.expected != "" && cands[0].Word != p.expected {
+		return batchWrong, cands[0].Word, nil
+	}
+	return outcome, cands[0].Word, nil
+}
+
+// batchResult is the outcome of a puzzle, Took is in nanoseconds in json
+type batchResult struct {
+	Kubraya  string        `json:"kubraya"`
+	Outcome  string        `json:"outcome"`
+	Answer   string        `json:"answer,omitempty"`
+	Expected string        `json:"expected,omitempty"`
+	Took     time.Duration `json:"took"`
+}
+
+func (b batchResult) line() string {
+	res := b.Outcome + ": " + b.Kubraya
+	if b.Answer != "" {
+		res += " -> " + b.Answer
+	}
+	if b.Outcome == batchWrong || (b.Outcome == batchFailed && b.Expected != "") {
+		res += ", want " + b.Expected
+	}
+
+	return res + " (" + b.Took.String() + ")"
+}
+
+// batchReport sums the outcomes of the puzzles up, Time is in nanoseconds in json
+type batchReport struct {
+	Puzzles     []batchResult  `json:"puzzles"`
+	Outcomes    map[string]int `json:"outcomes"`
+	Regressions int            `json:"regressions"`
+	Time        time.Duration  `json:"time"`
+}
+
+func (r batchReport) lines() []string {
+	res := []string{}
+	for _, p := range r.Puzzles {
+		res = append(res, p.line())
+	}
+
+	summary := []string{}
+	for _, outcome := range []string{batchSolved, batchFuzzy, batchGuessed, batchFailed, batchWrong} {
+		summary = append(summary, outcome+": "+strconv.Itoa(r.Outcomes[outcome]))
+	}
+	summary = append(summary, "time: "+r.Time.String())
+	if puzzles := len(r.Puzzles); puzzles > 0 {
+		summary = append(summary, "per puzzle: "+(r.Time/time.Duration(puzzles)).String())
+	}
+
+	return append(res, strings.Join(summary, ", "))
+}
+
+// runBatch solves every puzzle of the file and sums the outcomes up.
+// A puzzle with an expected answer that is not found first is a regression
+func runBatch(file string) (batchReport, error) {
+	res := batchReport{Puzzles: []batchResult{}, Outcomes: map[string]int{}}
+	lines, err := readFileToSlice(file, 100)
+	if err != nil {
+		return res, err
+	}
+
+	for _, line := range lines {
+		p, ok := parseBatchLine(line)
+		if !ok {
+			continue
+		}
+
+		start := timeNow()
+		outcome, answer, err := solveBatchPuzzle(p)
+		if err != nil {
+			return res, err
+		}
+		took := timeNow().Sub(start)
+
+		res.Time += took
+		res.Outcomes[outcome]++
+		if p.expected != "" && (outcome == batchWrong || outcome == batchFailed) {
+			res.Regressions++
+		}
+		res.Puzzles = append(res.Puzzles, batchResult{p.kubraya, outcome, answer, p.expected, took})
+	}
+
+	return res, nil
+}
+
+// exitCode tells how kubrai exits once it answered the verb. A batch fails when it can't run
+// or has regressions, so that scripts can tell. Other verbs exit 0
+func exitCode(verb string, r response) int {
+	if verb != vBatch {
+		return 0
+	}
+
+	if rep, ok := r.Results.(batchReport); r.Status != statusOK || (ok && rep.Regressions > 0) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruslanbes/kubrai/fileutils"
+)
+
+func setUpTestClock() {
+	now := time.Unix(0, 0)
+	timeNow = func() time.Time {
+		now = now.Add(time.Millisecond)
+		return now
+	}
+}
+
+func Test_runBatch(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propGuessMaxResults:       "50",
+		propGuessUnknownsLimit:    "2",
+		propGuessUnknownMarker:    "???",
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+		propDictsExt:              ".test",
+		propSolveFuzzyEdits:       "1",
+		propSolveMaxResults:       "5",
+	})
+	setUpTestClock()
+	defer func() { timeNow = time.Now }()
+
+	dictsDir := getFullDictsDir()
+	fileutils.FilePutContents(dictsDir+"/"+"dict.test", strings.Join(
+		[]string{
+			"boycott",
+			"cope",
+			"copy\t1000",
+		}, "\n"))
+	defer fileutils.FileRemove(dictsDir + "/" + "dict.test")
+
+	saveDefaultAssoc(map[string][]string{
+		"policeman": {"cop"},
+		"question":  {"y"},
+		"girl":      {"boy"},
+		"kid":       {"bo"},
+		"bed":       {"cot"},
+		"tea":       {"t"},
+	})
+
+	batchFile := "./test/data/puzzles.test"
+	fileutils.FilePutContents(batchFile, strings.Join(
+		[]string{
+			"# answers",
+			"policeman_question copy",
+			"",
+			"girl_bed_tea",
+			"kid_bed_tea boycott",
+			"policeman_what cope",
+			"why_not",
+		}, "\n"))
+	defer fileutils.FileRemove(batchFile)
+
+	want := []string{
+		"solved: policeman_question -> copy (1ms)",
+		"solved: girl_bed_tea -> boycott (1ms)",
+		"fuzzy: kid_bed_tea -> boycott (1ms)",
+		"wrong: policeman_what -> copy, want cope (1ms)",
+		"failed: why_not (1ms)",
+		"solved: 2, fuzzy: 1, guessed: 0, failed: 1, wrong: 1, time: 5ms, per puzzle: 1ms",
+	}
+	got, err := runBatch(batchFile)
+	if err != nil || !reflect.DeepEqual(got.lines(), want) {
+		t.Errorf("runBatch() = %q, %v, want %q, %v", got.lines(), err, want, nil)
+	}
+	if got.Regressions != 1 {
+		t.Errorf("runBatch() regressions = %v, want %v", got.Regressions, 1)
+	}
+
+	fileutils.FilePutContents(batchFile, "policeman_what\ngirl_bed_tea boycott")
+	want = []string{
+		"guessed: policeman_what -> copy (1ms)",
+		"solved: girl_bed_tea -> boycott (1ms)",
+		"solved: 1, fuzzy: 0, guessed: 1, failed: 0, wrong: 0, time: 2ms, per puzzle: 1ms",
+	}
+	got, err = runBatch(batchFile)
+	if err != nil || !reflect.DeepEqual(got.lines(), want) {
+		t.Errorf("runBatch() = %q, %v, want %q, %v", got.lines(), err, want, nil)
+	}
+	if got.Regressions != 0 {
+		t.Errorf("runBatch() regressions = %v, want %v", got.Regressions, 0)
+	}
+
+	if _, err := runBatch("./test/data/missing.test"); !os.IsNotExist(err) {
+		t.Errorf("runBatch() of a missing file error = %v, want not exist", err)
+	}
+}
+
+func Test_exitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		verb string
+		r    response
+		want int
+	}{
+		{
+			name: "BatchPassed",
+			verb: vBatch,
+			r:    response{Status: statusOK, Results: batchReport{}},
+			want: 0,
+		},
+		{
+			name: "BatchRegressed",
+			verb: vBatch,
+			r:    response{Status: statusOK, Results: batchReport{Regressions: 1}},
+			want: 1,
+		},
+		{
+			name: "BatchFailed",
+			verb: vBatch,
+			r:    statusResponse(statusInternalError),
+			want: 1,
+		},
+		{
+			name: "OtherVerbFailed",
+			verb: vSolve,
+			r:    statusResponse(statusNotFound),
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.verb, tt.r); got != tt.want {
+				t.Errorf("exitCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
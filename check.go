@@ -0,0 +1,154 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ruslanbes/kubrai/kubraya"
+	"github.com/ruslanbes/kubrai/property"
+	"github.com/ruslanbes/kubrai/solver"
+)
+
+// check verdicts
+const (
+	checkUnique    = "unique"
+	checkAmbiguous = "ambiguous"
+	checkNotFound  = "not found"
+)
+
+// checkRank tells where the answer is among the candidates, from 1, 0 if it is not there
+func checkRank(cands []solver.Candidate, answer string) int {
+	for i, c := range cands {
+		if c.Word == answer {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// checkPlace tells where a verb ranks the answer, Rank 0 if it doesn't find it
+type checkPlace struct {
+	Verb  string `json:"verb"`
+	Rank  int    `json:"rank"`
+	Count int    `json:"count"`
+}
+
+func newCheckPlace(verb string, cands []solver.Candidate, ok bool, answer string) checkPlace {
+	if !ok {
+		return checkPlace{Verb: verb}
+	}
+
+	return checkPlace{verb, checkRank(cands, answer), len(cands)}
+}
+
+func (p checkPlace) line() string {
+	if p.Rank == 0 {
+		return p.Verb + ": " + checkNotFound
+	}
+
+	return p.Verb + ": " + strconv.Itoa(p.Rank) + " of " + strconv.Itoa(p.Count)
+}
+
+// checkWeakPart is a part having no association, or more than CheckAmbiguousValues of them
+type checkWeakPart struct {
+	Part   string `json:"part"`
+	Values int    `json:"values"`
+}
+
+func (p checkWeakPart) line() string {
+	if p.Values == 0 {
+		return "unknown: " + p.Part
+	}
+
+	return "ambiguous: " + p.Part + " (" + strconv.Itoa(p.Values) + " values)"
+}
+
+// checkReport is what check finds about an answer
+type checkReport struct {
+	Verdict     string          `json:"verdict"`
+	Places      []checkPlace    `json:"places"`
+	Competitors []string        `json:"competitors"`
+	WeakParts   []checkWeakPart `json:"weak_parts"`
+}
+
+func (r checkReport) lines() []string {
+	res := []string{"check: " + r.Verdict}
+	for _, p := range r.Places {
+		res = append(res, p.line())
+	}
+	if len(r.Competitors) > 0 {
+		res = append(res, "competitors: "+strings.Join(r.Competitors, ", "))
+	}
+	for _, p := range r.WeakParts {
+		res = append(res, p.line())
+	}
+
+	return res
+}
+
+// checkCompetitors lists the other answers of the candidates, best first
+func checkCompetitors(answer string, candLists ...[]solver.Candidate) []string {
+	res := []string{}
+	seen := map[string]bool{answer: true}
+	for _, cands := range candLists {
+		for _, c := range cands {
+			if !seen[c.Word] {
+				seen[c.Word] = true
+				res = append(res, c.Word)
+			}
+		}
+	}
+
+	return res
+}
+
+// checkParts finds the weak parts of the kubraya
+func checkParts(input string) ([]checkWeakPart, error) {
+	maxValues := property.AsInt(propCheckAmbiguousValues)
+
+	res := []checkWeakPart{}
+	for _, part := range kubraya.SplitKubraya(input) {
+		vals, err := runView(part)
+		if err != nil {
+			return []checkWeakPart{}, err
+		}
+		if len(vals) == 0 || len(vals) > maxValues {
+			res = append(res, checkWeakPart{part, len(vals)})
+		}
+	}
+
+	return res, nil
+}
+
+// runCheck tells if the answer is the only one solve and guess find for the kubraya,
+// where they rank it, which answers compete with it and which parts are weak
+func runCheck(input, answer string) (checkReport, error) {
+	solved, solveErr := solveCandidates(input, solver.Constraint{})
+	if solveErr != nil && !notFound(solveErr) {
+		return checkReport{}, solveErr
+	}
+	guessed, guessErr := guessCandidates(input, solver.Constraint{})
+	if guessErr != nil && !notFound(guessErr) {
+		return checkReport{}, guessErr
+	}
+
+	res := checkReport{
+		Verdict: checkUnique,
+		Places: []checkPlace{
+			newCheckPlace(vSolve, solved, solveErr == nil, answer),
+			newCheckPlace(vGuess, guessed, guessErr == nil, answer),
+		},
+		Competitors: checkCompetitors(answer, solved, guessed),
+	}
+	switch {
+	case checkRank(solved, answer) == 0 && checkRank(guessed, answer) == 0:
+		res.Verdict = checkNotFound
+	case len(res.Competitors) > 0:
+		res.Verdict = checkAmbiguous
+	}
+
+	parts, err := checkParts(input)
+	res.WeakParts = parts
+	return res, err
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ruslanbes/kubrai/fileutils"
+)
+
+func Test_runCheck(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propCheckAmbiguousValues:  "3",
+		propGuessMaxResults:       "50",
+		propGuessUnknownsLimit:    "2",
+		propGuessUnknownMarker:    "???",
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+		propDictsExt:              ".test",
+		propSolveMaxResults:       "5",
+	})
+
+	dictsDir := getFullDictsDir()
+	fileutils.FilePutContents(dictsDir+"/"+"dict.test", strings.Join(
+		[]string{
+			"cope",
+			"copy\t1000",
+		}, "\n"))
+	defer fileutils.FileRemove(dictsDir + "/" + "dict.test")
+
+	saveDefaultAssoc(map[string][]string{
+		"policeman": {"cop", "bobby", "pig", "plod"},
+		"why":       {"y"},
+	})
+
+	tests := []struct {
+		name   string
+		input  string
+		answer string
+		want   []string
+	}{
+		{
+			name:   "Unique",
+			input:  "policeman_why",
+			answer: "copy",
+			want: []string{
+				"check: unique",
+				"solve: 1 of 1",
+				"guess: 1 of 1",
+				"ambiguous: policeman (4 values)",
+			},
+		},
+		{
+			name:   "Ambiguous",
+			input:  "policeman_what",
+			answer: "cope",
+			want: []string{
+				"check: ambiguous",
+				"solve: not found",
+				"guess: 2 of 2",
+				"competitors: copy",
+				"ambiguous: policeman (4 values)",
+				"unknown: what",
+			},
+		},
+		{
+			name:   "NotFound",
+			input:  "policeman_why",
+			answer: "cops",
+			want: []string{
+				"check: not found",
+				"solve: not found",
+				"guess: not found",
+				"competitors: copy",
+				"ambiguous: policeman (4 values)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := runCheck(tt.input, tt.answer); err != nil || !reflect.DeepEqual(got.lines(), tt.want) {
+				t.Errorf("runCheck() = %q, %v, want %q", got.lines(), err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/ruslanbes/kubrai/property"
+	"github.com/ruslanbes/kubrai/solver"
+)
+
+// composePuzzles finds the kubrayas of at least two parts whose answer is the word, best first
+func composePuzzles(word string) ([]solver.Puzzle, error) {
+	s, err := newSolver()
+	if err != nil {
+		return []solver.Puzzle{}, err
+	}
+
+	return s.Compose(context.Background(), word, solver.Options{MaxResults: property.AsInt(propComposeMaxResults)})
+}
+
+func formatPuzzle(p solver.Puzzle) string {
+	res := p.Kubraya
+	if property.AsBool(propGuessExplainResults) {
+		clues := make([]string, len(p.Clues))
+		for i, c := range p.Clues {
+			clues[i] = buildAssocString(c.Key, []string{c.Val})
+		}
+		res += " (" + strings.Join(clues, ", ") + ")"
+	}
+
+	// unlike scores, the lower the ambiguity the better
+	if property.AsBool(propShowScores) {
+		res += " (ambiguity " + strconv.Itoa(p.Ambiguity) + ")"
+	}
+	return res
+}
+
+func runComposeCommand(word string) response {
+	puzzles, err := composePuzzles(word)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	lines := make([]string, len(puzzles))
+	kubrayas := make([]string, len(puzzles))
+	for i, p := range puzzles {
+		lines[i] = formatPuzzle(p)
+		kubrayas[i] = p.Kubraya
+	}
+
+	r := newResponse(lines)
+	r.Results = kubrayas
+	r.Explanations = lines
+	return r
+}
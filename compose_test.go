@@ -0,0 +1,113 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ruslanbes/kubrai/property"
+	"github.com/ruslanbes/kubrai/solver"
+)
+
+func Test_runComposeCommand(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propComposeMaxResults:     "3",
+		propGuessExplainResults:   "ON",
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+		propShowScores:            "OFF",
+	})
+
+	saveDefaultAssoc(map[string][]string{
+		"policeman": {"cop", "bobby"},
+		"question":  {"y"},
+		"why":       {"y", "reason"},
+		"crow":      {"co"},
+		"pie":       {"py"},
+		"copy":      {"cop"},
+		"vine":      {"ivy"},
+	})
+
+	tests := []struct {
+		name  string
+		word  string
+		want  []string
+		want1 []string
+	}{
+		{
+			name: "Ranked",
+			word: "copy",
+			want: []string{
+				"crow_pie (crow:co, pie:py)",
+				"policeman_question (policeman:cop, question:y)",
+				"policeman_why (policeman:cop, why:y)",
+			},
+			want1: []string{"crow_pie", "policeman_question", "policeman_why"},
+		},
+		{
+			name:  "NotFound",
+			word:  "ivy",
+			want:  []string{statusNotFound},
+			want1: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := runComposeCommand(tt.word)
+			if got := strings.Split(r.text(), "\n"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runComposeCommand() text = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(r.Results, tt.want1) {
+				t.Errorf("runComposeCommand() results = %v, want %v", r.Results, tt.want1)
+			}
+		})
+	}
+}
+
+func Test_formatPuzzle(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+	})
+
+	p := solver.Puzzle{
+		Kubraya:   "policeman_why",
+		Clues:     []solver.Pair{{Key: "policeman", Val: "cop"}, {Key: "why", Val: "y"}},
+		Ambiguity: 1,
+	}
+	tests := []struct {
+		name    string
+		explain string
+		scores  string
+		want    string
+	}{
+		{
+			name:    "Plain",
+			explain: "OFF",
+			scores:  "OFF",
+			want:    "policeman_why",
+		},
+		{
+			name:    "Explained",
+			explain: "ON",
+			scores:  "OFF",
+			want:    "policeman_why (policeman:cop, why:y)",
+		},
+		{
+			name:    "Ambiguity",
+			explain: "OFF",
+			scores:  "ON",
+			want:    "policeman_why (ambiguity 1)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			property.SetProperties(map[string]string{propGuessExplainResults: tt.explain, propShowScores: tt.scores})
+			if got := formatPuzzle(p); got != tt.want {
+				t.Errorf("formatPuzzle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
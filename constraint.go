@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/ruslanbes/kubrai/solver"
+)
+
+// separators of a bound override such as question:1-2
+const (
+	boundSeparator = ":"
+	rangeSeparator = "-"
+)
+
+// errNotConstraint tells that an arg is not a constraint, so it is left to the verb
+var errNotConstraint = errors.New("not a constraint")
+
+// parseBound reads a range such as 1-2 or a single length such as 2.
+// A range over solver.MaxBound is a solver.ErrBadConstraint
+func parseBound(arg string) (solver.Bound, error) {
+	from, to := arg, arg
+	if i := strings.Index(arg, rangeSeparator); i >= 0 {
+		from, to = arg[:i], arg[i+len(rangeSeparator):]
+	}
+
+	min, err := strconv.Atoi(from)
+	if err != nil || min < 1 {
+		return solver.Bound{}, errNotConstraint
+	}
+	max, err := strconv.Atoi(to)
+	if err != nil || max < min {
+		return solver.Bound{}, errNotConstraint
+	}
+	if max > solver.MaxBound {
+		return solver.Bound{}, solver.ErrBadConstraint
+	}
+
+	return solver.Bound{Min: min, Max: max}, nil
+}
+
+// parseConstraint reads a length such as 8, a mask such as c??y or a bound override such as question:1-2
+func parseConstraint(arg string) (solver.Constraint, error) {
+	if n, err := strconv.Atoi(arg); err == nil {
+		if n < 1 {
+			return solver.Constraint{}, errNotConstraint
+		}
+		return solver.Constraint{Length: n}, nil
+	}
+	if i := strings.LastIndex(arg, boundSeparator); i > 0 {
+		b, err := parseBound(arg[i+len(boundSeparator):])
+		if err != nil {
+			return solver.Constraint{}, err
+		}
+		return solver.Constraint{Bounds: map[string]solver.Bound{arg[:i]: b}}, nil
+	}
+	if strings.ContainsRune(arg, solver.MaskAny) {
+		mask := []rune(arg)
+		return solver.Constraint{Length: len(mask), Mask: mask}, nil
+	}
+
+	return solver.Constraint{}, errNotConstraint
+}
+
+// parseConstraints splits the args into the constraint they make and the rest.
+// It fails when the args tell different lengths or a bound is too wide
+func parseConstraints(args []string) (solver.Constraint, []string, bool) {
+	res := solver.Constraint{}
+	rest := []string{}
+	for _, arg := range args {
+		c, err := parseConstraint(arg)
+		if err == errNotConstraint {
+			rest = append(rest, arg)
+			continue
+		}
+		if err != nil {
+			return solver.Constraint{}, rest, false
+		}
+		if c.Bounds != nil {
+			if res.Bounds == nil {
+				res.Bounds = map[string]solver.Bound{}
+			}
+			for part, b := range c.Bounds {
+				res.Bounds[part] = b
+			}
+			continue
+		}
+		if res.Length > 0 && res.Length != c.Length {
+			return solver.Constraint{}, rest, false
+		}
+		if res.Mask != nil && c.Mask != nil && string(res.Mask) != string(c.Mask) {
+			return solver.Constraint{}, rest, false
+		}
+
+		res.Length = c.Length
+		if c.Mask != nil {
+			res.Mask = c.Mask
+		}
+	}
+
+	return res, rest, true
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ruslanbes/kubrai/solver"
+)
+
+func Test_parseConstraints(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		want  solver.Constraint
+		want1 []string
+		want2 bool
+	}{
+		{
+			name:  "None",
+			args:  []string{"copy"},
+			want:  solver.Constraint{},
+			want1: []string{"copy"},
+			want2: true,
+		},
+		{
+			name:  "Length",
+			args:  []string{"8"},
+			want:  solver.Constraint{Length: 8},
+			want1: []string{},
+			want2: true,
+		},
+		{
+			name:  "Mask",
+			args:  []string{"c??y"},
+			want:  solver.Constraint{Length: 4, Mask: []rune("c??y")},
+			want1: []string{},
+			want2: true,
+		},
+		{
+			name:  "LengthAndMask",
+			args:  []string{"4", "c??y", "copy"},
+			want:  solver.Constraint{Length: 4, Mask: []rune("c??y")},
+			want1: []string{"copy"},
+			want2: true,
+		},
+		{
+			name:  "DifferentLengths",
+			args:  []string{"5", "c??y"},
+			want:  solver.Constraint{},
+			want1: []string{},
+			want2: false,
+		},
+		{
+			name:  "Bounds",
+			args:  []string{"question:1-2", "why:3", "copy"},
+			want:  solver.Constraint{Bounds: map[string]solver.Bound{"question": {Min: 1, Max: 2}, "why": {Min: 3, Max: 3}}},
+			want1: []string{"copy"},
+			want2: true,
+		},
+		{
+			name:  "BadBound",
+			args:  []string{"question:2-1"},
+			want:  solver.Constraint{},
+			want1: []string{"question:2-1"},
+			want2: true,
+		},
+		{
+			name:  "BoundTooWide",
+			args:  []string{"question:1-1001", "copy"},
+			want:  solver.Constraint{},
+			want1: []string{},
+			want2: false,
+		},
+		{
+			name:  "ZeroLength",
+			args:  []string{"0"},
+			want:  solver.Constraint{},
+			want1: []string{"0"},
+			want2: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1, got2 := parseConstraints(tt.args)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseConstraints() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("parseConstraints() got1 = %v, want %v", got1, tt.want1)
+			}
+			if got2 != tt.want2 {
+				t.Errorf("parseConstraints() got2 = %v, want %v", got2, tt.want2)
+			}
+		})
+	}
+}
@@ -0,0 +1,347 @@
+package dict
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IndexFile is the name of the index file kept in a dictionaries dir
+const IndexFile = ".kubrai.index"
+
+const (
+	indexMagic   = "KUBRAIDX"
+	indexVersion = 1
+)
+
+// ErrBadIndex is returned when an index file is not readable
+var ErrBadIndex = errors.New("bad dictionaries index")
+
+// Source is a dictionary file an index is built from
+type Source struct {
+	Name    string
+	Size    int64
+	ModTime int64 // unix nanoseconds
+	Hash    [sha256.Size]byte
+}
+
+// ListSources lists the dictionary files of the dir without hashing them
+func ListSources(dir, ext string) ([]Source, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []Source{}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ext) {
+			continue
+		}
+		res = append(res, Source{Name: f.Name(), Size: f.Size(), ModTime: f.ModTime().UnixNano()})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+
+	return res, nil
+}
+
+func hashFile(file string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+
+	f, err := os.Open(file)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// hashSources fills the hashes of the sources
+func hashSources(dir string, sources []Source) error {
+	for i := range sources {
+		sum, err := hashFile(filepath.Join(dir, sources[i].Name))
+		if err != nil {
+			return err
+		}
+		sources[i].Hash = sum
+	}
+
+	return nil
+}
+
+// upToDate tells if the indexed sources are the current ones.
+// A file with another modification time is still up to date when its hash is the same.
+// touched tells if the index needs rewriting with the new modification times
+func upToDate(dir string, indexed, current []Source) (ok bool, touched bool) {
+	if len(indexed) != len(current) {
+		return false, false
+	}
+
+	for i, cur := range current {
+		idx := indexed[i]
+		if idx.Name != cur.Name || idx.Size != cur.Size {
+			return false, false
+		}
+		if idx.ModTime == cur.ModTime {
+			current[i].Hash = idx.Hash
+			continue
+		}
+
+		sum, err := hashFile(filepath.Join(dir, cur.Name))
+		if err != nil || sum != idx.Hash {
+			return false, false
+		}
+		current[i].Hash = sum
+		touched = true
+	}
+
+	return true, touched
+}
+
+// LoadCached loads the set of the dir from its index file.
+// If the index is missing or outdated, the set is built with build and the index is rewritten.
+// Failing to write the index is not an error, the set is just built again next time.
+// Failing to build the set is
+func LoadCached(dir, ext string, build func() (*Set, error)) (*Set, error) {
+	current, err := ListSources(dir, ext)
+	if err != nil {
+		return build()
+	}
+
+	indexFile := filepath.Join(dir, IndexFile)
+	if f, err := os.Open(indexFile); err == nil {
+		set, indexed, err := ReadIndex(bufio.NewReader(f))
+		f.Close()
+		if err == nil {
+			if ok, touched := upToDate(dir, indexed, current); ok {
+				if touched {
+					writeIndexFile(indexFile, set, current)
+				}
+				return set, nil
+			}
+		}
+	}
+
+	set, err := build()
+	if err != nil {
+		return nil, err
+	}
+	if err := hashSources(dir, current); err == nil {
+		writeIndexFile(indexFile, set, current)
+	}
+	return set, nil
+}
+
+func writeIndexFile(indexFile string, set *Set, sources []Source) error {
+	tmpFile := indexFile + ".tmp"
+	f, err := os.Create(tmpFile)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	err = set.WriteIndex(w, sources)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpFile)
+		return err
+	}
+
+	return os.Rename(tmpFile, indexFile)
+}
+
+// indexWriter writes unsigned varints and strings, remembering the first error
+type indexWriter struct {
+	w   io.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func (iw *indexWriter) uint(v uint64) {
+	if iw.err != nil {
+		return
+	}
+	n := binary.PutUvarint(iw.buf[:], v)
+	_, iw.err = iw.w.Write(iw.buf[:n])
+}
+
+func (iw *indexWriter) bytes(b []byte) {
+	iw.uint(uint64(len(b)))
+	if iw.err != nil {
+		return
+	}
+	_, iw.err = iw.w.Write(b)
+}
+
+func (iw *indexWriter) ints(v []int) {
+	iw.uint(uint64(len(v)))
+	for _, i := range v {
+		iw.uint(uint64(i))
+	}
+}
+
+// WriteIndex writes the set and the sources it is built from in the index format
+func (s *Set) WriteIndex(w io.Writer, sources []Source) error {
+	s.index()
+	iw := &indexWriter{w: w}
+
+	iw.bytes([]byte(indexMagic))
+	iw.uint(indexVersion)
+
+	iw.uint(uint64(len(sources)))
+	for _, src := range sources {
+		iw.bytes([]byte(src.Name))
+		iw.uint(uint64(src.Size))
+		iw.uint(uint64(src.ModTime))
+		iw.bytes(src.Hash[:])
+	}
+
+	dicts := []string{}
+	dictNums := make(map[string]int)
+	for _, e := range s.entries {
+		if _, ok := dictNums[e.Dict]; !ok {
+			dictNums[e.Dict] = len(dicts)
+			dicts = append(dicts, e.Dict)
+		}
+	}
+	iw.uint(uint64(len(dicts)))
+	for _, d := range dicts {
+		iw.bytes([]byte(d))
+	}
+
+	iw.uint(uint64(len(s.entries)))
+	for _, e := range s.entries {
+		iw.bytes([]byte(e.Word))
+		iw.uint(uint64(dictNums[e.Dict]))
+		iw.uint(uint64(e.Line))
+		iw.uint(uint64(e.Freq))
+	}
+
+	iw.ints(s.sorted)
+	iw.ints(s.byLen)
+	iw.ints(s.lenHist)
+
+	return iw.err
+}
+
+// indexReader reads what indexWriter writes, remembering the first error
+type indexReader struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (ir *indexReader) uint() uint64 {
+	if ir.err != nil {
+		return 0
+	}
+	var v uint64
+	v, ir.err = binary.ReadUvarint(ir.r)
+	return v
+}
+
+func (ir *indexReader) count(max int) int {
+	n := ir.uint()
+	if n > uint64(max) && ir.err == nil {
+		ir.err = ErrBadIndex
+	}
+	if ir.err != nil {
+		return 0
+	}
+	return int(n)
+}
+
+func (ir *indexReader) bytes() []byte {
+	n := ir.count(1 << 20)
+	if ir.err != nil {
+		return nil
+	}
+	b := make([]byte, n)
+	_, ir.err = io.ReadFull(ir.r, b)
+	return b
+}
+
+func (ir *indexReader) ints(max, upper int) []int {
+	n := ir.count(max)
+	res := make([]int, n)
+	for i := range res {
+		res[i] = int(ir.uint())
+		if res[i] >= upper && ir.err == nil {
+			ir.err = ErrBadIndex
+		}
+	}
+	return res
+}
+
+// ReadIndex reads a set and the sources it was built from
+func ReadIndex(r *bufio.Reader) (*Set, []Source, error) {
+	ir := &indexReader{r: r}
+
+	if magic := ir.bytes(); ir.err == nil && !bytes.Equal(magic, []byte(indexMagic)) {
+		return nil, nil, ErrBadIndex
+	}
+	if version := ir.uint(); ir.err == nil && version != indexVersion {
+		return nil, nil, ErrBadIndex
+	}
+
+	sources := make([]Source, ir.count(1<<16))
+	for i := range sources {
+		sources[i].Name = string(ir.bytes())
+		sources[i].Size = int64(ir.uint())
+		sources[i].ModTime = int64(ir.uint())
+		copy(sources[i].Hash[:], ir.bytes())
+	}
+
+	dicts := make([]string, ir.count(1<<16))
+	for i := range dicts {
+		dicts[i] = string(ir.bytes())
+	}
+
+	// entries are appended one by one so that a broken count can't allocate much
+	s := NewSet()
+	for i, n := 0, ir.count(1<<30); i < n && ir.err == nil; i++ {
+		e := Entry{Word: string(ir.bytes())}
+		if d := ir.uint(); d < uint64(len(dicts)) {
+			e.Dict = dicts[d]
+		} else if ir.err == nil {
+			ir.err = ErrBadIndex
+		}
+		e.Line = int(ir.uint())
+		e.Freq = int(ir.uint())
+		s.entries = append(s.entries, e)
+	}
+
+	n := len(s.entries)
+	s.sorted = ir.ints(n, n)
+	s.byLen = ir.ints(n, n)
+	s.lenHist = ir.ints(1<<20, n+1)
+	if ir.err != nil {
+		return nil, nil, ErrBadIndex
+	}
+	if len(s.sorted) != n || len(s.byLen) != n {
+		return nil, nil, ErrBadIndex
+	}
+
+	s.indexWords()
+	s.indexed = true
+	return s, sources, nil
+}
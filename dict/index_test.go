@@ -0,0 +1,178 @@
+package dict
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruslanbes/kubrai/fileutils"
+)
+
+func TestSet_WriteIndex(t *testing.T) {
+	s := newTestSet()
+	sources := []Source{{Name: "a.txt", Size: 10, ModTime: 42}, {Name: "b.txt", Size: 20, ModTime: 43}}
+	sources[1].Hash[0] = 7
+
+	var buf bytes.Buffer
+	if err := s.WriteIndex(&buf, sources); err != nil {
+		t.Fatalf("WriteIndex() error = %v", err)
+	}
+
+	got, gotSources, err := ReadIndex(bufio.NewReader(&buf))
+	if err != nil {
+		t.Fatalf("ReadIndex() error = %v", err)
+	}
+	if !reflect.DeepEqual(gotSources, sources) {
+		t.Errorf("ReadIndex() sources = %v, want %v", gotSources, sources)
+	}
+	if !reflect.DeepEqual(got.entries, s.entries) {
+		t.Errorf("ReadIndex() entries = %v, want %v", got.entries, s.entries)
+	}
+	if !reflect.DeepEqual(got.LenHistogram(), s.LenHistogram()) {
+		t.Errorf("ReadIndex() histogram = %v, want %v", got.LenHistogram(), s.LenHistogram())
+	}
+	if want := s.Lookup("abc"); !reflect.DeepEqual(got.Lookup("abc"), want) {
+		t.Errorf("ReadIndex() Lookup() = %v, want %v", got.Lookup("abc"), want)
+	}
+	re := regexp.MustCompile("^.+c.*$")
+	if want := s.Match(re, 0, 0, 50); !reflect.DeepEqual(got.Match(re, 0, 0, 50), want) {
+		t.Errorf("ReadIndex() Match() = %v, want %v", got.Match(re, 0, 0, 50), want)
+	}
+	if !got.Trie().Contains("папа") {
+		t.Errorf("ReadIndex() Trie() misses папа")
+	}
+}
+
+func TestReadIndex_Bad(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "Empty",
+			data: "",
+		},
+		{
+			name: "Magic",
+			data: "\x08NOTANIDX\x01",
+		},
+		{
+			name: "Truncated",
+			data: "\x08" + indexMagic + "\x01\x05",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ReadIndex(bufio.NewReader(strings.NewReader(tt.data))); err == nil {
+				t.Errorf("ReadIndex() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestLoadCached(t *testing.T) {
+	dir := "../test/data/dicts"
+	os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+	os.MkdirAll(dir, 0777)
+	fileutils.FilePutContents(dir+"/a.test", "copy\nboycott")
+
+	builds := 0
+	build := func() (*Set, error) {
+		builds++
+		s := NewSet()
+		lines := []string{"copy", "boycott"}
+		if builds > 1 {
+			lines = []string{"copy", "boycott", "proximity"}
+		}
+		s.AddDict("a.test", lines)
+		return s, nil
+	}
+
+	steps := []struct {
+		name       string
+		change     func()
+		wantBuilds int
+		wantLen    int
+	}{
+		{
+			name:       "Build",
+			change:     func() {},
+			wantBuilds: 1,
+			wantLen:    2,
+		},
+		{
+			name:       "Cached",
+			change:     func() {},
+			wantBuilds: 1,
+			wantLen:    2,
+		},
+		{
+			name: "Touched",
+			change: func() {
+				later := time.Now().Add(time.Hour)
+				os.Chtimes(dir+"/a.test", later, later)
+			},
+			wantBuilds: 1,
+			wantLen:    2,
+		},
+		{
+			name: "Modified",
+			change: func() {
+				fileutils.FilePutContents(dir+"/a.test", "copy\nboycott\nproximity")
+			},
+			wantBuilds: 2,
+			wantLen:    3,
+		},
+		{
+			name: "OtherFilesIgnored",
+			change: func() {
+				fileutils.FilePutContents(dir+"/notes.md", "not a dict")
+			},
+			wantBuilds: 2,
+			wantLen:    3,
+		},
+	}
+	for _, st := range steps {
+		t.Run(st.name, func(t *testing.T) {
+			st.change()
+			s, err := LoadCached(dir, ".test", build)
+			if err != nil {
+				t.Fatalf("LoadCached() error = %v", err)
+			}
+			if builds != st.wantBuilds {
+				t.Errorf("LoadCached() builds = %v, want %v", builds, st.wantBuilds)
+			}
+			if s.Len() != st.wantLen {
+				t.Errorf("LoadCached() Len() = %v, want %v", s.Len(), st.wantLen)
+			}
+			if _, err := os.Stat(filepath.Join(dir, IndexFile)); err != nil {
+				t.Errorf("LoadCached() index file: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadCached_buildError(t *testing.T) {
+	dir := "../test/data/dicts"
+	os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+	os.MkdirAll(dir, 0777)
+	fileutils.FilePutContents(dir+"/a.test", "copy")
+
+	want := errors.New("unreadable")
+	s, err := LoadCached(dir, ".test", func() (*Set, error) { return nil, want })
+	if s != nil || err != want {
+		t.Errorf("LoadCached() = %v, %v, want nil, %v", s, err, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, IndexFile)); err == nil {
+		t.Errorf("LoadCached() wrote the index of a failed build")
+	}
+}
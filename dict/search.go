@@ -0,0 +1,188 @@
+package dict
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// search modes
+const (
+	ModeExact     = "exact"     // the word itself
+	ModePrefix    = "prefix"    // words starting with the word
+	ModeSuffix    = "suffix"    // words ending with the word
+	ModeSubstring = "substring" // words containing the word
+	ModeFuzzy     = "fuzzy"     // words within an edit distance of the word
+	ModeAnagram   = "anagram"   // words made of the same letters
+)
+
+// ErrUnknownMode is returned when searching in a mode that doesn't exist
+var ErrUnknownMode = errors.New("unknown search mode")
+
+// Found is an entry found by a search.
+// Distance is the edit distance for fuzzy search and the number of extra runes for
+// prefix, suffix and substring search
+type Found struct {
+	Entry
+	Distance int
+}
+
+// Search finds the entries for the word in the mode, closest first.
+// maxDistance only applies to fuzzy search
+func (s *Set) Search(word, mode string, maxDistance, maxResults int) ([]Found, error) {
+	var res []Found
+	switch mode {
+	case ModeExact:
+		res = s.found(s.lookup(word), func(string) int { return 0 })
+	case ModePrefix:
+		res = s.found(s.withPrefix(word), extraRunes(word))
+	case ModeSuffix:
+		res = s.filter(utf8.RuneCountInString(word), 0, func(w string) bool {
+			return strings.HasSuffix(w, word)
+		}, extraRunes(word))
+	case ModeSubstring:
+		res = s.filter(utf8.RuneCountInString(word), 0, func(w string) bool {
+			return strings.Contains(w, word)
+		}, extraRunes(word))
+	case ModeFuzzy:
+		res = s.fuzzy(word, maxDistance)
+	case ModeAnagram:
+		key := anagramKey(word)
+		l := utf8.RuneCountInString(word)
+		res = s.filter(l, l, func(w string) bool {
+			return anagramKey(w) == key
+		}, func(string) int { return 0 })
+	default:
+		return []Found{}, ErrUnknownMode
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Distance < res[j].Distance
+	})
+	if maxResults >= 0 && len(res) > maxResults {
+		res = res[:maxResults]
+	}
+	return res, nil
+}
+
+func extraRunes(word string) func(string) int {
+	l := utf8.RuneCountInString(word)
+	return func(w string) int {
+		return utf8.RuneCountInString(w) - l
+	}
+}
+
+func (s *Set) found(entries []int, distance func(string) int) []Found {
+	sorted := make([]int, len(entries))
+	copy(sorted, entries)
+	sort.Ints(sorted)
+
+	res := make([]Found, len(sorted))
+	for i, e := range sorted {
+		res[i] = Found{s.entries[e], distance(s.entries[e].Word)}
+	}
+	return res
+}
+
+// filter finds the entries of minLen to maxLen runes matching, in dictionary order
+func (s *Set) filter(minLen, maxLen int, match func(string) bool, distance func(string) int) []Found {
+	matched := []int{}
+	for _, e := range s.entriesOfLen(minLen, maxLen) {
+		if match(s.entries[e].Word) {
+			matched = append(matched, e)
+		}
+	}
+	return s.found(matched, distance)
+}
+
+// withPrefix gets the positions of the words starting with the prefix in the sorted entries
+func (s *Set) withPrefix(prefix string) []int {
+	s.index()
+	from := sort.Search(len(s.sorted), func(i int) bool {
+		return s.entries[s.sorted[i]].Word >= prefix
+	})
+	to := from
+	for to < len(s.sorted) && strings.HasPrefix(s.entries[s.sorted[to]].Word, prefix) {
+		to++
+	}
+
+	return s.sorted[from:to]
+}
+
+func (s *Set) fuzzy(word string, maxDistance int) []Found {
+	runes := []rune(word)
+	minLen := len(runes) - maxDistance
+	if minLen < 0 {
+		minLen = 0
+	}
+
+	distance := func(w string) int {
+		return EditDistance(runes, []rune(w), maxDistance)
+	}
+	return s.filter(minLen, len(runes)+maxDistance, func(w string) bool {
+		return distance(w) <= maxDistance
+	}, distance)
+}
+
+// EditDistance counts the insertions, deletions, substitutions and transpositions of
+// adjacent runes turning a into b (optimal string alignment distance).
+// Counting stops at max, max+1 is returned for anything farther
+func EditDistance(a, b []rune, max int) int {
+	if d := len(a) - len(b); d > max || -d > max {
+		return max + 1
+	}
+
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d := minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d = minInt(d, prev2[j-2]+1)
+			}
+			cur[j] = d
+			if d < rowMin {
+				rowMin = d
+			}
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+
+	if prev[len(b)] > max {
+		return max + 1
+	}
+	return prev[len(b)]
+}
+
+func minInt(vals ...int) int {
+	m := vals[0]
+	for _, v := range vals[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+func anagramKey(word string) string {
+	runes := []rune(word)
+	sort.Slice(runes, func(i, j int) bool {
+		return runes[i] < runes[j]
+	})
+	return string(runes)
+}
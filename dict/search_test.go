@@ -0,0 +1,138 @@
+package dict
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSet_Search(t *testing.T) {
+	s := NewSet()
+	s.AddDict("a.txt", []string{"copy", "cope", "coypu", "cop", "copycat", "policeman", "ypoc"})
+	s.AddDict("b.txt", []string{"copy"})
+
+	type args struct {
+		word        string
+		mode        string
+		maxDistance int
+		maxResults  int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []Found
+		wantErr error
+	}{
+		{
+			name: "Exact",
+			args: args{"copy", ModeExact, 0, 10},
+			want: []Found{{Entry{"copy", "a.txt", 0, 0}, 0}, {Entry{"copy", "b.txt", 0, 0}, 0}},
+		},
+		{
+			name: "Prefix",
+			args: args{"copy", ModePrefix, 0, 10},
+			want: []Found{{Entry{"copy", "a.txt", 0, 0}, 0}, {Entry{"copy", "b.txt", 0, 0}, 0}, {Entry{"copycat", "a.txt", 4, 0}, 3}},
+		},
+		{
+			name: "Suffix",
+			args: args{"man", ModeSuffix, 0, 10},
+			want: []Found{{Entry{"policeman", "a.txt", 5, 0}, 6}},
+		},
+		{
+			name: "Substring",
+			args: args{"yc", ModeSubstring, 0, 10},
+			want: []Found{{Entry{"copycat", "a.txt", 4, 0}, 5}},
+		},
+		{
+			name: "Fuzzy",
+			args: args{"copy", ModeFuzzy, 1, 10},
+			want: []Found{
+				{Entry{"copy", "a.txt", 0, 0}, 0},
+				{Entry{"copy", "b.txt", 0, 0}, 0},
+				{Entry{"cope", "a.txt", 1, 0}, 1},
+				{Entry{"cop", "a.txt", 3, 0}, 1},
+			},
+		},
+		{
+			name: "FuzzyLimited",
+			args: args{"copy", ModeFuzzy, 2, 3},
+			want: []Found{
+				{Entry{"copy", "a.txt", 0, 0}, 0},
+				{Entry{"copy", "b.txt", 0, 0}, 0},
+				{Entry{"cope", "a.txt", 1, 0}, 1},
+			},
+		},
+		{
+			name: "Anagram",
+			args: args{"pyco", ModeAnagram, 0, 10},
+			want: []Found{{Entry{"copy", "a.txt", 0, 0}, 0}, {Entry{"ypoc", "a.txt", 6, 0}, 0}, {Entry{"copy", "b.txt", 0, 0}, 0}},
+		},
+		{
+			name:    "Unknown",
+			args:    args{"copy", "psychic", 0, 10},
+			want:    []Found{},
+			wantErr: ErrUnknownMode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Search(tt.args.word, tt.args.mode, tt.args.maxDistance, tt.args.maxResults)
+			if err != tt.wantErr {
+				t.Errorf("Search() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	type args struct {
+		a   string
+		b   string
+		max int
+	}
+	tests := []struct {
+		name string
+		args args
+		want int
+	}{
+		{
+			name: "Same",
+			args: args{"copy", "copy", 2},
+			want: 0,
+		},
+		{
+			name: "Substitution",
+			args: args{"copy", "cope", 2},
+			want: 1,
+		},
+		{
+			name: "Transposition",
+			args: args{"copy", "cpoy", 2},
+			want: 1,
+		},
+		{
+			name: "InsertAndDelete",
+			args: args{"copy", "ocopt", 3},
+			want: 2,
+		},
+		{
+			name: "TooFar",
+			args: args{"copy", "policeman", 2},
+			want: 3,
+		},
+		{
+			name: "Cyrillic",
+			args: args{"мама", "папа", 2},
+			want: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EditDistance([]rune(tt.args.a), []rune(tt.args.b), tt.args.max); got != tt.want {
+				t.Errorf("EditDistance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
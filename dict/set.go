@@ -0,0 +1,232 @@
+package dict
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FreqSeparator separates an optional word frequency from the word in a dictionary line
+const FreqSeparator = "\t"
+
+// Entry is a word of a dictionary
+type Entry struct {
+	Word string
+	Dict string // dictionary name
+	Line int    // line of the word in the dictionary, from 0
+	Freq int    // word frequency, 0 if not given
+}
+
+// Set is a set of dictionaries indexed for lookups.
+// The indexes are built on first lookup after adding entries
+type Set struct {
+	entries []Entry
+	words   map[string][]int // entries of every word
+	sorted  []int            // entries ordered by word
+	byLen   []int            // entries ordered by word length in runes, then in dictionary order
+	lenHist []int            // how many entries have a word of every length
+	trie    *Trie
+	indexed bool
+}
+
+// NewSet creates an empty set
+func NewSet() *Set {
+	return &Set{entries: []Entry{}}
+}
+
+// ParseLine splits a dictionary line into the word and its frequency, 0 if not given
+func ParseLine(line string) (string, int) {
+	i := strings.Index(line, FreqSeparator)
+	if i == -1 {
+		return line, 0
+	}
+
+	freq, err := strconv.Atoi(strings.TrimSpace(line[i+len(FreqSeparator):]))
+	if err != nil {
+		return line[:i], 0
+	}
+	return line[:i], freq
+}
+
+// AddDict adds all lines of the dictionary
+func (s *Set) AddDict(name string, lines []string) {
+	for i, line := range lines {
+		word, freq := ParseLine(line)
+		s.Add(Entry{Word: word, Dict: name, Line: i, Freq: freq})
+	}
+}
+
+// Add adds the entry
+func (s *Set) Add(e Entry) {
+	s.entries = append(s.entries, e)
+	s.indexed = false
+	s.trie = nil
+}
+
+// Len tells how many entries are in the set
+func (s *Set) Len() int {
+	return len(s.entries)
+}
+
+func (s *Set) index() {
+	if s.indexed {
+		return
+	}
+
+	n := len(s.entries)
+	s.sorted = make([]int, n)
+	s.lenHist = []int{}
+	lens := make([]int, n)
+	for i, e := range s.entries {
+		s.sorted[i] = i
+		l := utf8.RuneCountInString(e.Word)
+		lens[i] = l
+		for len(s.lenHist) <= l {
+			s.lenHist = append(s.lenHist, 0)
+		}
+		s.lenHist[l]++
+	}
+
+	sort.SliceStable(s.sorted, func(i, j int) bool {
+		return s.entries[s.sorted[i]].Word < s.entries[s.sorted[j]].Word
+	})
+
+	// a counting sort keeps every length in dictionary order
+	next := make([]int, len(s.lenHist))
+	for l := 1; l < len(s.lenHist); l++ {
+		next[l] = next[l-1] + s.lenHist[l-1]
+	}
+	s.byLen = make([]int, n)
+	for i, l := range lens {
+		s.byLen[next[l]] = i
+		next[l]++
+	}
+
+	s.indexWords()
+	s.indexed = true
+}
+
+// indexWords builds the hash set of the words
+func (s *Set) indexWords() {
+	s.words = make(map[string][]int, len(s.entries))
+	for i, e := range s.entries {
+		s.words[e.Word] = append(s.words[e.Word], i)
+	}
+}
+
+// Trie gets the prefix tree of the words
+func (s *Set) Trie() *Trie {
+	if s.trie == nil {
+		s.trie = NewTrie()
+		for _, e := range s.entries {
+			s.trie.Insert(e.Word)
+		}
+	}
+
+	return s.trie
+}
+
+// LenHistogram tells how many entries have a word of every length in runes
+func (s *Set) LenHistogram() []int {
+	s.index()
+	return s.lenHist
+}
+
+// lookup gets the entries of the word in dictionary order
+func (s *Set) lookup(word string) []int {
+	s.index()
+	return s.words[word]
+}
+
+// Contains tells if the word is in any dictionary
+func (s *Set) Contains(word string) bool {
+	return len(s.lookup(word)) > 0
+}
+
+// Lookup gets all entries of the word
+func (s *Set) Lookup(word string) []Entry {
+	found := s.lookup(word)
+	res := make([]Entry, len(found))
+	for i, e := range found {
+		res[i] = s.entries[e]
+	}
+
+	return res
+}
+
+// Freq gets the highest frequency the word is given
+func (s *Set) Freq(word string) int {
+	freq := 0
+	for _, e := range s.lookup(word) {
+		if s.entries[e].Freq > freq {
+			freq = s.entries[e].Freq
+		}
+	}
+
+	return freq
+}
+
+// Match gets the words matching the regexp, shorter words first, words as long in dictionary order.
+// Only words of minLen to maxLen runes are tried, maxLen 0 means no limit
+func (s *Set) Match(re *regexp.Regexp, minLen, maxLen, maxResults int) []string {
+	return s.MatchAll([]*regexp.Regexp{re}, minLen, maxLen, maxResults)
+}
+
+// MatchAll is Match for the words matching every regexp
+func (s *Set) MatchAll(res []*regexp.Regexp, minLen, maxLen, maxResults int) []string {
+	results := []string{}
+	if maxResults == 0 {
+		return results
+	}
+
+	found := make(map[string]bool)
+	for _, i := range s.entriesOfLen(minLen, maxLen) {
+		word := s.entries[i].Word
+		if !found[word] && matchesAll(res, word) {
+			found[word] = true
+			results = append(results, word)
+			if len(results) == maxResults {
+				break
+			}
+		}
+	}
+
+	return results
+}
+
+func matchesAll(res []*regexp.Regexp, word string) bool {
+	for _, re := range res {
+		if !re.MatchString(word) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// entriesOfLen gets the entries of minLen to maxLen runes, shorter words first,
+// words as long in dictionary order. The entries are shared with the index
+func (s *Set) entriesOfLen(minLen, maxLen int) []int {
+	s.index()
+	if maxLen == 0 || maxLen >= len(s.lenHist) {
+		maxLen = len(s.lenHist) - 1
+	}
+
+	from := 0
+	to := 0
+	for l, count := range s.lenHist {
+		if l < minLen {
+			from += count
+		}
+		if l <= maxLen {
+			to += count
+		}
+	}
+	if from >= to {
+		return []int{}
+	}
+
+	return s.byLen[from:to]
+}
@@ -0,0 +1,149 @@
+package dict
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func newTestSet() *Set {
+	s := NewSet()
+	s.AddDict("a.txt", []string{"aabbcc", "abc\t10", "bcd", "папа"})
+	s.AddDict("b.txt", []string{"abc\t1000", "def"})
+	return s
+}
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		want  string
+		want1 int
+	}{
+		{
+			name:  "Plain",
+			line:  "copy",
+			want:  "copy",
+			want1: 0,
+		},
+		{
+			name:  "Freq",
+			line:  "copy\t1234",
+			want:  "copy",
+			want1: 1234,
+		},
+		{
+			name:  "BadFreq",
+			line:  "copy\tmany",
+			want:  "copy",
+			want1: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := ParseLine(tt.line)
+			if got != tt.want {
+				t.Errorf("ParseLine() got = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("ParseLine() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestSet_Lookup(t *testing.T) {
+	s := newTestSet()
+
+	tests := []struct {
+		name     string
+		word     string
+		want     []Entry
+		wantFreq int
+	}{
+		{
+			name:     "Two",
+			word:     "abc",
+			want:     []Entry{{"abc", "a.txt", 1, 10}, {"abc", "b.txt", 0, 1000}},
+			wantFreq: 1000,
+		},
+		{
+			name:     "None",
+			word:     "ab",
+			want:     []Entry{},
+			wantFreq: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Lookup(tt.word); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lookup() = %v, want %v", got, tt.want)
+			}
+			if got := s.Contains(tt.word); got != (len(tt.want) > 0) {
+				t.Errorf("Contains() = %v, want %v", got, len(tt.want) > 0)
+			}
+			if got := s.Freq(tt.word); got != tt.wantFreq {
+				t.Errorf("Freq() = %v, want %v", got, tt.wantFreq)
+			}
+		})
+	}
+}
+
+func TestSet_Match(t *testing.T) {
+	s := newTestSet()
+
+	type args struct {
+		re         *regexp.Regexp
+		minLen     int
+		maxLen     int
+		maxResults int
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "NoLimit",
+			args: args{regexp.MustCompile("^.+c.*$"), 0, 0, 50},
+			want: []string{"abc", "bcd", "aabbcc"},
+		},
+		{
+			name: "MinLen",
+			args: args{regexp.MustCompile("^.+c.*$"), 4, 0, 50},
+			want: []string{"aabbcc"},
+		},
+		{
+			name: "MaxLen",
+			args: args{regexp.MustCompile("^.+$"), 4, 4, 50},
+			want: []string{"папа"},
+		},
+		{
+			name: "MaxResults",
+			args: args{regexp.MustCompile("^.+$"), 0, 0, 2},
+			want: []string{"abc", "bcd"},
+		},
+		{
+			name: "Zero",
+			args: args{regexp.MustCompile("^.+$"), 0, 0, 0},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Match(tt.args.re, tt.args.minLen, tt.args.maxLen, tt.args.maxResults); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSet_MatchAll(t *testing.T) {
+	s := newTestSet()
+
+	res := []*regexp.Regexp{regexp.MustCompile("^.+c.*$"), regexp.MustCompile("^a")}
+	want := []string{"abc", "aabbcc"}
+	if got := s.MatchAll(res, 0, 0, 50); !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchAll() = %v, want %v", got, want)
+	}
+}
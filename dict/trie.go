@@ -0,0 +1,97 @@
+package dict
+
+import "sort"
+
+// Trie is a prefix tree of dictionary words
+type Trie struct {
+	root *Node
+	size int
+}
+
+// Node is a prefix in the trie
+type Node struct {
+	children map[rune]*Node
+	word     bool
+}
+
+// NewTrie creates an empty trie
+func NewTrie() *Trie {
+	return &Trie{root: newNode()}
+}
+
+func newNode() *Node {
+	return &Node{children: make(map[rune]*Node)}
+}
+
+// Insert adds the word to the trie
+func (t *Trie) Insert(word string) {
+	n := t.root
+	for _, r := range word {
+		next, ok := n.children[r]
+		if !ok {
+			next = newNode()
+			n.children[r] = next
+		}
+		n = next
+	}
+
+	if !n.word {
+		n.word = true
+		t.size++
+	}
+}
+
+// Len tells how many words are in the trie
+func (t *Trie) Len() int {
+	return t.size
+}
+
+// Root gets the node of the empty prefix
+func (t *Trie) Root() *Node {
+	return t.root
+}
+
+// Contains tells if the word is in the trie
+func (t *Trie) Contains(word string) bool {
+	n := t.root.Walk(word)
+	return n != nil && n.word
+}
+
+// HasPrefix tells if any word in the trie starts with the prefix
+func (t *Trie) HasPrefix(prefix string) bool {
+	return t.root.Walk(prefix) != nil
+}
+
+// Walk follows the chunk down from the node.
+// Returns nil if no word continues this way
+func (n *Node) Walk(chunk string) *Node {
+	for _, r := range chunk {
+		next, ok := n.children[r]
+		if !ok {
+			return nil
+		}
+		n = next
+	}
+
+	return n
+}
+
+// IsWord tells if the prefix of the node is a word itself
+func (n *Node) IsWord() bool {
+	return n.word
+}
+
+// EachChild calls fn for every letter continuing the prefix of the node, in rune order
+func (n *Node) EachChild(fn func(r rune, child *Node)) {
+	runes := make([]rune, 0, len(n.children))
+	for r := range n.children {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool {
+		return runes[i] < runes[j]
+	})
+
+	for _, r := range runes {
+		fn(r, n.children[r])
+	}
+}
@@ -0,0 +1,103 @@
+package dict
+
+import (
+	"testing"
+)
+
+func TestTrie(t *testing.T) {
+	trie := NewTrie()
+	for _, w := range []string{"copy", "cop", "boycott", "папа", "copy"} {
+		trie.Insert(w)
+	}
+
+	if got := trie.Len(); got != 4 {
+		t.Errorf("Len() = %v, want %v", got, 4)
+	}
+
+	tests := []struct {
+		name       string
+		word       string
+		wantWord   bool
+		wantPrefix bool
+	}{
+		{
+			name:       "Word",
+			word:       "copy",
+			wantWord:   true,
+			wantPrefix: true,
+		},
+		{
+			name:       "WordAndPrefix",
+			word:       "cop",
+			wantWord:   true,
+			wantPrefix: true,
+		},
+		{
+			name:       "Prefix",
+			word:       "boy",
+			wantWord:   false,
+			wantPrefix: true,
+		},
+		{
+			name:       "Empty",
+			word:       "",
+			wantWord:   false,
+			wantPrefix: true,
+		},
+		{
+			name:       "Cyrillic",
+			word:       "па",
+			wantWord:   false,
+			wantPrefix: true,
+		},
+		{
+			name:       "None",
+			word:       "copies",
+			wantWord:   false,
+			wantPrefix: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trie.Contains(tt.word); got != tt.wantWord {
+				t.Errorf("Contains() = %v, want %v", got, tt.wantWord)
+			}
+			if got := trie.HasPrefix(tt.word); got != tt.wantPrefix {
+				t.Errorf("HasPrefix() = %v, want %v", got, tt.wantPrefix)
+			}
+		})
+	}
+}
+
+func TestNode_Walk(t *testing.T) {
+	trie := NewTrie()
+	trie.Insert("boycott")
+
+	n := trie.Root().Walk("boy")
+	if n == nil || n.IsWord() {
+		t.Fatalf("Walk(boy) = %v, want a non-word node", n)
+	}
+	if n = n.Walk("cott"); n == nil || !n.IsWord() {
+		t.Errorf("Walk(cott) = %v, want a word node", n)
+	}
+	if n = trie.Root().Walk("boys"); n != nil {
+		t.Errorf("Walk(boys) = %v, want nil", n)
+	}
+}
+
+func TestNode_EachChild(t *testing.T) {
+	trie := NewTrie()
+	for _, w := range []string{"copy", "cope", "cops", "coypu"} {
+		trie.Insert(w)
+	}
+
+	got := ""
+	trie.Root().Walk("cop").EachChild(func(r rune, child *Node) {
+		if child.IsWord() {
+			got += string(r)
+		}
+	})
+	if got != "esy" {
+		t.Errorf("EachChild() visited %q, want %q", got, "esy")
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"github.com/ruslanbes/kubrai/kubraya"
+	"github.com/ruslanbes/kubrai/solver"
+)
+
+// playbookError tells that the playbook doesn't exist
+type playbookError struct {
+	playbook string
+}
+
+func (e *playbookError) Error() string {
+	return "playbook not found: " + e.playbook
+}
+
+// assocLineError tells that a line of an association file can't be read and why
+type assocLineError struct {
+	file   string
+	line   int // from 1
+	text   string
+	reason string
+}
+
+func (e *assocLineError) Error() string {
+	return e.file + ":" + strconv.Itoa(e.line) + ": malformed association, " + e.reason + ": " + e.text
+}
+
+// assocVersionError tells that the association file has a header of a version this build can't read
+type assocVersionError struct {
+	file   string
+	header string
+}
+
+func (e *assocVersionError) Error() string {
+	return e.file + ": unsupported association file version: " + e.header
+}
+
+// dictError tells that a dictionary can't be read
+type dictError struct {
+	dict string
+	err  error
+}
+
+func (e *dictError) Error() string {
+	return "can't read dictionary " + e.dict + ": " + e.err.Error()
+}
+
+func (e *dictError) Unwrap() error {
+	return e.err
+}
+
+// partCountError tells that two kubrayas that should match have different numbers of parts
+type partCountError struct {
+	src    string
+	target string
+}
+
+func (e *partCountError) Error() string {
+	return "part count mismatch: " + e.src + " has " + strconv.Itoa(len(kubraya.SplitKubraya(e.src))) +
+		", " + e.target + " has " + strconv.Itoa(len(kubraya.SplitKubraya(e.target)))
+}
+
+// notFound tells if the error only means that there is no answer
+func notFound(err error) bool {
+	var unknownPart *solver.UnknownPartError
+	return err == solver.ErrNotFound || errors.As(err, &unknownPart)
+}
+
+// errorResponse makes the failed response telling what went wrong
+func errorResponse(err error) response {
+	var playbook *playbookError
+	var partCount *partCountError
+	switch {
+	case notFound(err):
+		return statusResponse(statusNotFound)
+	case errors.As(err, &playbook), errors.Is(err, os.ErrNotExist):
+		return statusResponse(statusNotFound, err.Error())
+	case err == solver.ErrBadConstraint:
+		return statusResponse(statusBadRequest)
+	case errors.As(err, &partCount):
+		return statusResponse(statusBadRequest, err.Error())
+	default:
+		return statusResponse(statusInternalError, err.Error())
+	}
+}
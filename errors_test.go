@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/ruslanbes/kubrai/solver"
+)
+
+func Test_errorResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "NotFound",
+			err:  solver.ErrNotFound,
+			want: "404 NOT FOUND",
+		},
+		{
+			name: "UnknownPart",
+			err:  &solver.UnknownPartError{Part: "why"},
+			want: "404 NOT FOUND",
+		},
+		{
+			name: "MissingPlaybook",
+			err:  &playbookError{"missing"},
+			want: "404 NOT FOUND\nplaybook not found: missing",
+		},
+		{
+			name: "MissingFile",
+			err:  &os.PathError{Op: "open", Path: "puzzles.txt", Err: os.ErrNotExist},
+			want: "404 NOT FOUND\nopen puzzles.txt: file does not exist",
+		},
+		{
+			name: "BadConstraint",
+			err:  solver.ErrBadConstraint,
+			want: "400 BAD REQUEST",
+		},
+		{
+			name: "PartCountMismatch",
+			err:  &partCountError{"policeman_why", "copy"},
+			want: "400 BAD REQUEST\npart count mismatch: policeman_why has 2, copy has 1",
+		},
+		{
+			name: "MalformedAssociation",
+			err:  &assocLineError{"associations.txt", 3, "why y", reasonNoKeySep},
+			want: "500 INTERNAL SERVER ERROR\nassociations.txt:3: malformed association, missing key separator: why y",
+		},
+		{
+			name: "UnreadableDict",
+			err:  &dictError{"dict.txt", errors.New("is a directory")},
+			want: "500 INTERNAL SERVER ERROR\ncan't read dictionary dict.txt: is a directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorResponse(tt.err).text(); got != tt.want {
+				t.Errorf("errorResponse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/ruslanbes/kubrai/solver"
+)
+
+// fuzzyJoin joins the chunks of a fuzzy solution when it is explained
+const fuzzyJoin = "+"
+
+// formatFuzzy explains the edits of a fuzzy solution
+func formatFuzzy(c solver.Candidate) string {
+	explained := make([]string, len(c.Edits))
+	for i, e := range c.Edits {
+		explained[i] = e.Explain(c.Chunks)
+	}
+
+	return strings.Join(c.Chunks, fuzzyJoin) + " -> " + c.Word + " (" + strings.Join(explained, ", ") + ")"
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ruslanbes/kubrai/fileutils"
+	"github.com/ruslanbes/kubrai/property"
+	"github.com/ruslanbes/kubrai/solver"
+)
+
+func Test_fuzzySolveCandidates(t *testing.T) {
+	props := map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+		propDictsExt:              ".test",
+		propShowScores:            "OFF",
+		propSolveMaxResults:       "5",
+	}
+	setUpTestProperties(props)
+
+	dictsDir := getFullDictsDir()
+	fileutils.FilePutContents(dictsDir+"/"+"dict.test", strings.Join(
+		[]string{
+			"boycott",
+			"copy",
+		}, "\n"))
+	defer fileutils.FileRemove(dictsDir + "/" + "dict.test")
+
+	assoc := make(map[string][]string)
+	assoc["policeman"] = []string{"cop"}
+	assoc["why"] = []string{"py"}
+	assoc["kid"] = []string{"bo"}
+	assoc["lad"] = []string{"boys"}
+	assoc["bed"] = []string{"cot"}
+	assoc["tea"] = []string{"t"}
+	assoc["teas"] = []string{"tt"}
+	saveDefaultAssoc(assoc)
+
+	type args struct {
+		kubraya string
+		budget  string
+	}
+	tests := []struct {
+		name  string
+		args  args
+		want  []string
+		want1 bool
+	}{
+		{
+			name:  "Merged",
+			args:  args{"policeman_why", "1"},
+			want:  []string{"cop+py -> copy (merged p of cop and py)"},
+			want1: true,
+		},
+		{
+			name:  "Inserted",
+			args:  args{"kid_bed_tea", "1"},
+			want:  []string{"bo+cot+t -> boycott (inserted y between bo and cot)"},
+			want1: true,
+		},
+		{
+			name:  "Dropped",
+			args:  args{"lad_bed_tea", "1"},
+			want:  []string{"boys+cot+t -> boycott (dropped s of boys)"},
+			want1: true,
+		},
+		{
+			name:  "OverBudget",
+			args:  args{"kid_bed_teas", "1"},
+			want:  []string{},
+			want1: false,
+		},
+		{
+			name:  "TwoEdits",
+			args:  args{"kid_bed_teas", "2"},
+			want:  []string{"bo+cot+tt -> boycott (inserted y between bo and cot, merged t of cot and tt)"},
+			want1: true,
+		},
+		{
+			name:  "Off",
+			args:  args{"policeman_why", "0"},
+			want:  []string{},
+			want1: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props[propSolveFuzzyEdits] = tt.args.budget
+			property.SetProperties(props)
+
+			cands, err := fuzzySolveCandidates(tt.args.kubraya, solver.Constraint{})
+			got := make([]string, len(cands))
+			for i, c := range cands {
+				got[i] = formatCandidate(c)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fuzzySolveCandidates() got = %v, want %v", got, tt.want)
+			}
+			if got1 := err == nil; got1 != tt.want1 {
+				t.Errorf("fuzzySolveCandidates() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
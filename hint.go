@@ -0,0 +1,153 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ruslanbes/kubrai/kubraya"
+	"github.com/ruslanbes/kubrai/solver"
+)
+
+// hint levels, each one gives away more than the previous
+const (
+	hintAssoc  = 1 // known associations of one part
+	hintLength = 2 // answer length
+	hintLetter = 3 // answer first letter
+	hintAnswer = 4 // the answer itself
+)
+
+func runHintCommand(args []string) response {
+	level := hintAssoc
+	if len(args) > 1 {
+		num, err := strconv.Atoi(args[1])
+		if err != nil || num < hintAssoc {
+			return statusResponse(statusBadRequest)
+		}
+		level = num
+	}
+
+	res, err := runHint(args[0], level)
+	if err != nil {
+		return errorResponse(err)
+	}
+	r := newResponse(hintLines(res))
+	r.Results = res
+	return r
+}
+
+// hint is what a hint level gives away
+type hint struct {
+	Level int    `json:"level"`
+	Hint  string `json:"hint"`
+}
+
+func (h hint) line() string {
+	return "hint " + strconv.Itoa(h.Level) + ": " + h.Hint
+}
+
+func hintLines(hints []hint) []string {
+	res := make([]string, len(hints))
+	for i, h := range hints {
+		res[i] = h.line()
+	}
+
+	return res
+}
+
+// runHint gives hints for a kubraya up to the level, solver.ErrNotFound if there are none
+func runHint(input string, level int) ([]hint, error) {
+	if level > hintAnswer {
+		level = hintAnswer
+	}
+
+	kubAssoc, _, err := buildKubAssocComplete(input)
+	if err != nil {
+		return []hint{}, err
+	}
+
+	res := []hint{}
+	if known, ok := hintKnownAssoc(input, kubAssoc); ok {
+		res = append(res, hint{hintAssoc, known})
+	}
+	if level == hintAssoc {
+		return hintsFound(res)
+	}
+
+	cands, err := solveCandidates(input, solver.Constraint{})
+	if notFound(err) {
+		cands, err = guessCandidates(input, solver.Constraint{})
+	}
+	if notFound(err) {
+		return hintsFound(res)
+	}
+	if err != nil {
+		return []hint{}, err
+	}
+
+	words := solver.Words(cands)
+	res = append(res, hint{hintLength, hintWordLengths(words)})
+	if level >= hintLetter {
+		res = append(res, hint{hintLetter, hintFirstLetters(words)})
+	}
+	if level >= hintAnswer {
+		res = append(res, hint{hintAnswer, strings.Join(words, ", ")})
+	}
+
+	return res, nil
+}
+
+func hintsFound(res []hint) ([]hint, error) {
+	if len(res) == 0 {
+		return res, solver.ErrNotFound
+	}
+
+	return res, nil
+}
+
+func hintKnownAssoc(input string, kubAssoc [][]string) (string, bool) {
+	for i, part := range kubraya.SplitKubraya(input) {
+		if len(kubAssoc[i]) > 0 {
+			return buildAssocString(part, kubAssoc[i]), true
+		}
+	}
+
+	return "", false
+}
+
+func hintWordLengths(words []string) string {
+	found := make(map[int]bool)
+	lengths := []int{}
+	for _, w := range words {
+		l := len([]rune(w))
+		if !found[l] {
+			found[l] = true
+			lengths = append(lengths, l)
+		}
+	}
+	sort.Ints(lengths)
+
+	res := make([]string, len(lengths))
+	for i, l := range lengths {
+		res[i] = strconv.Itoa(l)
+	}
+	return strings.Join(res, " or ") + " letters"
+}
+
+func hintFirstLetters(words []string) string {
+	found := make(map[string]bool)
+	letters := []string{}
+	for _, w := range words {
+		if w == "" {
+			continue
+		}
+		l := canonize(string([]rune(w)[:1]))
+		if !found[l] {
+			found[l] = true
+			letters = append(letters, l)
+		}
+	}
+	sort.Strings(letters)
+
+	return "starts with " + strings.Join(letters, " or ")
+}
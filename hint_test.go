@@ -0,0 +1,142 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ruslanbes/kubrai/fileutils"
+)
+
+func Test_runHint(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propGuessMaxResults:       "50",
+		propGuessUnknownsLimit:    "2",
+		propGuessUnknownMarker:    "???",
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+		propDictsExt:              ".test",
+		propSolveMaxResults:       "5",
+	})
+
+	dictsDir := getFullDictsDir()
+	fileutils.FilePutContents(dictsDir+"/"+"dict.test", strings.Join(
+		[]string{
+			"boycott",
+			"copy",
+		}, "\n"))
+	defer fileutils.FileRemove(dictsDir + "/" + "dict.test")
+
+	assoc := make(map[string][]string)
+	assoc["girl"] = []string{"boy", "woman"}
+	assoc["bed"] = []string{"cot"}
+	assoc["tea"] = []string{"t"}
+	saveDefaultAssoc(assoc)
+
+	type args struct {
+		kubraya string
+		level   int
+	}
+	tests := []struct {
+		name  string
+		args  args
+		want  []string
+		want1 bool
+	}{
+		{
+			name:  "Assoc",
+			args:  args{"girl_bed_tea", 1},
+			want:  []string{"hint 1: girl:boy,woman"},
+			want1: true,
+		},
+		{
+			name:  "Letter",
+			args:  args{"girl_bed_tea", 3},
+			want:  []string{"hint 1: girl:boy,woman", "hint 2: 7 letters", "hint 3: starts with B"},
+			want1: true,
+		},
+		{
+			name:  "AnswerClamped",
+			args:  args{"girl_bed_tea", 9},
+			want:  []string{"hint 1: girl:boy,woman", "hint 2: 7 letters", "hint 3: starts with B", "hint 4: boycott"},
+			want1: true,
+		},
+		{
+			name:  "UnknownFirstPart",
+			args:  args{"why_bed", 2},
+			want:  []string{"hint 1: bed:cot"},
+			want1: true,
+		},
+		{
+			name:  "NothingKnown",
+			args:  args{"why_not", 4},
+			want:  []string{},
+			want1: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runHint(tt.args.kubraya, tt.args.level)
+			if !reflect.DeepEqual(hintLines(got), tt.want) {
+				t.Errorf("runHint() got = %v, want %v", hintLines(got), tt.want)
+			}
+			if got1 := err == nil; got1 != tt.want1 {
+				t.Errorf("runHint() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func Test_hintWordLengths(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		want  string
+	}{
+		{
+			name:  "One",
+			words: []string{"copy", "cope"},
+			want:  "4 letters",
+		},
+		{
+			name:  "Many",
+			words: []string{"boycott", "copy", "мама"},
+			want:  "4 or 7 letters",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hintWordLengths(tt.words); got != tt.want {
+				t.Errorf("hintWordLengths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_hintFirstLetters(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		want  string
+	}{
+		{
+			name:  "One",
+			words: []string{"copy", "cope"},
+			want:  "starts with C",
+		},
+		{
+			name:  "Many",
+			words: []string{"мама", "copy", "boycott"},
+			want:  "starts with B or C or М",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hintFirstLetters(tt.words); got != tt.want {
+				t.Errorf("hintFirstLetters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
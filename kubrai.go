@@ -2,19 +2,21 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"os/user"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/ruslanbes/kubrai/dict"
 	"github.com/ruslanbes/kubrai/kubraya"
 	"github.com/ruslanbes/kubrai/property"
+	"github.com/ruslanbes/kubrai/solver"
 )
 
 // verbs
@@ -22,6 +24,9 @@ const (
 	vAdd         = "add"         // assoc add
 	vAddBoth     = "addboth"     // assoc addboth
 	vAddSolution = "addsolution" // assoc addsolution
+	vBatch       = "batch"       // playbook batch
+	vCheck       = "check"       // playbook check
+	vCompose     = "compose"     // playbook compose
 	vGuess       = "guess"       // playbook guess
 	vHint        = "hint"        // playbook hint
 	vPlay        = "play"        // playbook play
@@ -30,6 +35,7 @@ const (
 	vRemoveBoth  = "removeboth"  // assoc removeboth
 	vSearchDict  = "searchdict"  // dict search
 	vSolve       = "solve"       // playbook solve
+	vNormalize   = "normalize"   // assoc normalize
 	vUndo        = "undo"        // assoc undo
 	vView        = "view"        // assoc view
 )
@@ -43,15 +49,24 @@ const (
 	propAddValMayEqualKey           = "AddValMayEqualKey"
 	propAssocFileKeySeparator       = "AssocFileKeySeparator"
 	propAssocFileValSeparator       = "AssocFileValSeparator"
+	propAssocStore                  = "AssocStore"           // text or log. Undo needs text, a new log starts from the text file
+	propCheckAmbiguousValues        = "CheckAmbiguousValues" // parts with more values are flagged by check
+	propComposeMaxResults           = "ComposeMaxResults"
 	propDictsExt                    = "DictsExt"
 	propGuessExplainResults         = "GuessExplainResults"
+	propGuessBoundUnknowns          = "GuessBoundUnknowns" // limit unknown parts to the value lengths of the playbook
 	propGuessMaxResults             = "GuessMaxResults"
 	propGuessUnknownMarker          = "GuessUnknownMarker"
 	propGuessUnknownsLimit          = "GuessUnknownsLimit"
 	propPlaybookCurrent             = "PlaybookCurrent"
 	propPlaybooksDir                = "PlaybooksDir"
+	propSearchDictDefaultDistance   = "SearchDictDefaultDistance"
 	propSearchDictDefaultMaxResults = "SearchDictDefaultMaxResults"
+	propShowScores                  = "ShowScores"
 	propSolveAutoGuess              = "SolveAutoGuess"
+	propSolveAutolearn              = "SolveAutolearn"
+	propSolveAutolearnStep          = "SolveAutolearnStep" // max new associations learned from one solution
+	propSolveFuzzyEdits             = "SolveFuzzyEdits"    // max edits where parts join when nothing solves exactly
 	propSolveMaxResults             = "SolveMaxResults"
 )
 
@@ -69,8 +84,8 @@ func findExactVerb(args []string) string {
 	return ""
 }
 
-func getPossibleVerbs() [13]string {
-	return [...]string{vAdd, vAddBoth, vAddSolution, vRemove, vRemoveBoth, vView, vSearchDict, vSolve, vGuess, vHint, vPlay, vUndo, vPlaybook}
+func getPossibleVerbs() [17]string {
+	return [...]string{vAdd, vAddBoth, vAddSolution, vRemove, vRemoveBoth, vView, vSearchDict, vSolve, vGuess, vHint, vPlay, vUndo, vPlaybook, vCompose, vCheck, vBatch, vNormalize}
 }
 
 func guessVerb(args []string) string {
@@ -150,45 +165,18 @@ func extractArgs(verb string, args []string) []string {
 	return filterOut(verb, args)
 }
 
-func checkError(e error) {
-	if e != nil {
-		log.Fatal(e)
-	}
-}
-
-func loadAssoc(assocFile string) map[string][]string {
-	f, err := os.Open(assocFile)
-	checkError(err)
-	defer f.Close()
-
-	assoc := make(map[string][]string)
-
-	keySep := property.AsString(propAssocFileKeySeparator)
-	valSep := property.AsString(propAssocFileValSeparator)
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		keyVals := strings.Split(scanner.Text(), keySep)
-		key := keyVals[0]
-		vals := strings.Split(keyVals[1], valSep)
-		assoc[key] = vals
-	}
-
-	if err := scanner.Err(); err != nil {
-		log.Fatal(err)
-	}
-
-	return assoc
-}
-
 func backupName(file string, backupNum int) string {
 	return file + "." + strconv.Itoa(backupNum) + ".bak"
 }
 
+// maxBackups is how many previous versions of a file are kept
+const maxBackups = 10
+
 func rotateBackups(file string) {
-	lastBakFile := backupName(file, 10)
+	lastBakFile := backupName(file, maxBackups)
 	os.Remove(lastBakFile)
 
-	for i := 99; i > 0; i-- {
+	for i := maxBackups - 1; i > 0; i-- {
 		bacFile := backupName(file, i)
 		nextBacFile := backupName(file, i+1)
 		os.Rename(bacFile, nextBacFile)
@@ -200,6 +188,19 @@ func backupFile(file string) {
 	os.Rename(file, backupName(file, 1))
 }
 
+// commandBackups keeps the files the running command has backed up. A command backs a file up
+// on its first save only, so that undo reverts whole commands. Out of a command every save backs up
+var commandBackups map[string]bool
+
+// beginCommand starts a command of the user, see commandBackups
+func beginCommand() {
+	commandBackups = map[string]bool{}
+}
+
+func endCommand() {
+	commandBackups = nil
+}
+
 func canonize(word string) string {
 	return strings.Trim(strings.ToUpper(word), " ")
 }
@@ -208,34 +209,30 @@ func buildAssocString(word string, assocSingle []string) string {
 	return word + property.AsString(propAssocFileKeySeparator) + strings.Join(assocSingle, property.AsString(propAssocFileValSeparator))
 }
 
-func saveAssoc(assocFile string, assoc map[string][]string) {
-	backupFile(assocFile)
+func saveAssoc(assocFile string, assoc map[string][]string) error {
+	if !commandBackups[assocFile] {
+		backupFile(assocFile)
+		if commandBackups != nil {
+			commandBackups[assocFile] = true
+		}
+	}
 
 	os.MkdirAll(filepath.Dir(assocFile), 0777)
-	f, err := os.Create(assocFile)
-	checkError(err)
-	defer f.Close()
-
-	for k, v := range assoc {
-		f.WriteString(buildAssocString(k, v) + "\n")
-	}
+	return ioutil.WriteFile(assocFile, []byte(formatAssoc(assoc)), 0666)
 }
 
+// playbook layout
+const (
+	assocFileLocation = "associations/associations.txt"
+	dictsDir          = "dicts"
+)
+
 func getFullAssocFileLocation() string {
-	assocFileLocation := "associations/associations.txt"
 	playbookDir := getCurrentPlaybookDir()
 
 	return playbookDir + "/" + assocFileLocation
 }
 
-func saveDefaultAssoc(assoc map[string][]string) {
-	saveAssoc(getFullAssocFileLocation(), assoc)
-}
-
-func loadDefaultAssoc() map[string][]string {
-	return loadAssoc(getFullAssocFileLocation())
-}
-
 func addBeforeFirstLonger(s string, slc []string) []string {
 	longer := -1
 	for i, w := range slc {
@@ -260,58 +257,68 @@ func addBeforeFirstLonger(s string, slc []string) []string {
 	return res
 }
 
-func runAdd(a, b string) []string {
-	assoc := loadDefaultAssoc()
+func runAdd(a, b string) ([]string, error) {
+	store, err := openDefaultStore()
+	if err != nil {
+		return []string{}, err
+	}
 
 	if a != b || a == b && property.AsBool(propAddValMayEqualKey) {
-		assoc[a] = addBeforeFirstLonger(b, assoc[a])
-		saveDefaultAssoc(assoc)
+		return store.Add(a, b)
 	}
 
-	return assoc[a]
+	return store.Get(a), nil
 }
 
-func runAddBoth(a, b string) [2][]string {
+func runAddBoth(a, b string) ([2][]string, error) {
 	var res [2][]string
-	res[0] = runAdd(a, b)
-	res[1] = runAdd(b, a)
-	return res
+	var err error
+	if res[0], err = runAdd(a, b); err != nil {
+		return res, err
+	}
+	res[1], err = runAdd(b, a)
+	return res, err
 }
 
-func runAddSolution(src, target string) map[string][]string {
+// runAddSolution adds the parts of the target as associations of the parts of src.
+// Both must have as many parts, otherwise it is a partCountError
+func runAddSolution(src, target string) (map[string][]string, error) {
 	partsSrc := kubraya.SplitKubraya(src)
 	partsTarget := kubraya.SplitKubraya(target)
 	if len(partsSrc) != len(partsTarget) {
-		log.Fatal("nothing")
+		return map[string][]string{}, &partCountError{src, target}
 	}
 
 	res := map[string][]string{}
 	for i := range partsSrc {
-		addRes := runSmartAdd(partsSrc[i], partsTarget[i])
+		addRes, err := runSmartAdd(partsSrc[i], partsTarget[i])
+		if err != nil {
+			return res, err
+		}
 
 		for k, v := range addRes {
 			res[k] = v
 		}
 	}
 
-	return res
+	return res, nil
 }
 
-func runSmartAdd(a, b string) map[string][]string {
+func runSmartAdd(a, b string) (map[string][]string, error) {
 	if kubraya.IsKubraya(a) && kubraya.IsKubraya(b) {
 		return runAddSolution(a, b)
 	}
 
 	if len([]rune(a)) <= property.AsInt(propAddAutoBothMaxlen) {
-		tmp := runAddBoth(a, b)
+		tmp, err := runAddBoth(a, b)
 		res := map[string][]string{}
 		res[a] = tmp[0]
 		res[b] = tmp[1]
-		return res
+		return res, err
 	}
 
-	res := map[string][]string{a: runAdd(a, b)}
-	return res
+	res, err := runAdd(a, b)
+	return map[string][]string{a: res}, err
 }
 
 func removeByValue(s string, slc []string) []string {
@@ -324,51 +331,49 @@ func removeByValue(s string, slc []string) []string {
 	return slc
 }
 
-func runRemove(a, b string) []string {
-	assoc := loadDefaultAssoc()
-	if assoca, ok := assoc[a]; ok {
-		assoca = removeByValue(b, assoca)
-		if len(assoca) == 0 {
-			delete(assoc, a)
-		} else {
-			assoc[a] = assoca
-		}
-		saveDefaultAssoc(assoc)
-		return assoca
+func runRemove(a, b string) ([]string, error) {
+	store, err := openDefaultStore()
+	if err != nil {
+		return []string{}, err
 	}
 
-	return []string{}
+	return store.Remove(a, b)
 }
 
-func runRemoveBoth(a, b string) [2][]string {
+func runRemoveBoth(a, b string) ([2][]string, error) {
 	var res [2][]string
-	res[0] = runRemove(a, b)
-	res[1] = runRemove(b, a)
-	return res
+	var err error
+	if res[0], err = runRemove(a, b); err != nil {
+		return res, err
+	}
+	res[1], err = runRemove(b, a)
+	return res, err
 }
 
-func runView(a string) []string {
-	assoc := loadDefaultAssoc()
-	if assoca, ok := assoc[a]; ok {
-		return assoca
+func runView(a string) ([]string, error) {
+	store, err := openDefaultStore()
+	if err != nil {
+		return []string{}, err
 	}
-	return []string{}
+
+	return store.Get(a), nil
 }
 
-func readDirNames(dir string) []string {
+func readDirNames(dir string) ([]string, error) {
 	f, err := os.Open(dir)
+	if err != nil {
+		return []string{}, err
+	}
 	defer f.Close()
-	checkError(err)
 
-	list, err := f.Readdirnames(-1)
-	checkError(err)
-
-	return list
+	return f.Readdirnames(-1)
 }
 
-func readFileToSlice(file string, cap int) []string {
+func readFileToSlice(file string, cap int) ([]string, error) {
 	f, err := os.Open(file)
-	checkError(err)
+	if err != nil {
+		return []string{}, err
+	}
 	defer f.Close()
 
 	slc := make([]string, 0, cap)
@@ -378,356 +383,541 @@ func readFileToSlice(file string, cap int) []string {
 		slc = append(slc, scanner.Text())
 	}
 
-	if err := scanner.Err(); err != nil {
-		log.Fatal(err)
-	}
-
-	return slc
+	return slc, scanner.Err()
 }
 
-func getCurrentPlaybookDir() string {
-	playbook := property.AsString(propPlaybookCurrent)
+func getPlaybookDir(playbook string) string {
 	playbooksDir := property.AsString(propPlaybooksDir)
 
 	return playbooksDir + "/" + playbook
 }
 
+func getCurrentPlaybookDir() string {
+	return getPlaybookDir(property.AsString(propPlaybookCurrent))
+}
+
 func getFullDictsDir() string {
-	dictsDir := "dicts"
 	playbookDir := getCurrentPlaybookDir()
 	return playbookDir + "/" + dictsDir
 }
 
-func loadDicts() map[string][]string {
+func loadDicts() (map[string][]string, error) {
 	dictsDir := getFullDictsDir()
 	dictsExt := property.AsString(propDictsExt)
-	lenExt := len(dictsExt)
-	list := readDirNames(dictsDir)
+	list, err := readDirNames(dictsDir)
+	if err != nil {
+		return map[string][]string{}, &dictError{dictsDir, err}
+	}
 
 	dicts := make(map[string][]string)
 	for _, n := range list {
-		if n[len(n)-lenExt:] != dictsExt {
+		if !strings.HasSuffix(n, dictsExt) {
 			continue
 		}
 
-		dicts[n] = readFileToSlice(dictsDir+"/"+n, 200000)
+		if dicts[n], err = readFileToSlice(dictsDir+"/"+n, 200000); err != nil {
+			return map[string][]string{}, &dictError{n, err}
+		}
+	}
+
+	return dicts, nil
+}
+
+func sortedDictNames(dicts map[string][]string) []string {
+	names := make([]string, 0, len(dicts))
+	for n := range dicts {
+		names = append(names, n)
 	}
+	sort.Strings(names)
 
-	return dicts
+	return names
 }
 
-func nextMultiDimValue(counter, maxCounter []int) ([]int, bool) {
-	if len(counter) != len(maxCounter) {
-		log.Println(fmt.Errorf("WARN : Counter length is incorrect: %d != %d", len(counter), len(maxCounter)))
+// loadedDictSet is a dict set with the state of the dicts it was loaded from
+type loadedDictSet struct {
+	fingerprint string
+	set         *dict.Set
+}
+
+// dictSets keeps the dict sets loaded by this process
+var dictSets = map[string]loadedDictSet{}
+
+// dictsFingerprint changes whenever a dict is added, removed or modified
+func dictsFingerprint(dictsDir string) (string, error) {
+	dictsExt := property.AsString(propDictsExt)
+	files, err := ioutil.ReadDir(dictsDir)
+	if err != nil {
+		return "", &dictError{dictsDir, err}
 	}
 
-	ok := false
-	for i, c := range counter {
-		if c != maxCounter[i] {
-			counter[i] = c + 1
-			ok = true
-			break
-		} else {
-			counter[i] = 0
+	var b strings.Builder
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), dictsExt) {
+			continue
 		}
+		fmt.Fprintf(&b, "%s:%d:%d;", f.Name(), f.Size(), f.ModTime().UnixNano())
 	}
 
-	return counter, ok
+	return b.String(), nil
 }
 
-func combinations(items [][]string) [][]string {
-	lenitems := len(items)
+// loadDictSet loads the dicts once per process and reloads them only when they change.
+// The dicts are read from the index file of the dicts dir unless it is outdated
+func loadDictSet() (*dict.Set, error) {
+	if playbook := property.AsString(propPlaybookCurrent); !playbookExists(playbook) {
+		return nil, &playbookError{playbook}
+	}
 
-	maxCounter := make([]int, lenitems)
-	for i, item := range items {
-		maxCounter[i] = len(item) - 1
+	dictsDir := getFullDictsDir()
+	fingerprint, err := dictsFingerprint(dictsDir)
+	if err != nil {
+		return nil, err
+	}
+	if loaded, ok := dictSets[dictsDir]; ok && loaded.fingerprint == fingerprint {
+		return loaded.set, nil
 	}
 
-	ok := true
-	counter := make([]int, lenitems)
-	combs := make([][]string, 0, lenitems*10)
-	for true {
-		comb := make([]string, lenitems)
-		for i, j := range counter {
-			comb[i] = items[i][j]
+	set, err := dict.LoadCached(dictsDir, property.AsString(propDictsExt), func() (*dict.Set, error) {
+		set := dict.NewSet()
+		dicts, err := loadDicts()
+		if err != nil {
+			return nil, err
 		}
-		combs = append(combs, comb)
-
-		counter, ok = nextMultiDimValue(counter, maxCounter)
-		if !ok {
-			break
+		for _, name := range sortedDictNames(dicts) {
+			set.AddDict(name, dicts[name])
 		}
+		return set, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return combs
+	dictSets[dictsDir] = loadedDictSet{fingerprint, set}
+	return set, nil
 }
 
-func runSearchDict(word string, maxResults int) map[string]int {
-	// improve it with fuzzy search
-	dicts := loadDicts()
-
+func runSearchDict(word string, maxResults int) (map[string]int, error) {
 	results := make(map[string]int)
 	if maxResults == 0 {
-		return results
-	}
-	for dictName, dict := range dicts {
-		for line, dictWord := range dict {
-			if word == dictWord {
-				results[dictName] = line
-				if len(results) == maxResults {
-					return results
-				}
-			}
+		return results, nil
+	}
+	set, err := loadDictSet()
+	if err != nil {
+		return results, err
+	}
+	for _, e := range set.Lookup(word) {
+		results[e.Dict] = e.Line
+		if len(results) == maxResults {
+			return results, nil
 		}
 	}
 
-	return results
+	return results, nil
 }
 
-func searchDictByRegexpGetWords(re *regexp.Regexp, maxResults int) []string {
-	dicts := loadDicts()
-
-	results := make([]string, 0, maxResults)
-	if maxResults == 0 {
-		return results
-	}
-	for _, dict := range dicts {
-		for _, dictWord := range dict {
-			if re.MatchString(dictWord) {
-				results = append(results, dictWord)
-				if len(results) == maxResults {
-					return results
-				}
-			}
-		}
+// runSearchDictMode searches the dicts in one of the dict search modes, closest first.
+// Searching needs no associations
+func runSearchDictMode(word, mode string, maxDistance int) ([]dict.Found, error) {
+	set, err := loadDictSet()
+	if err != nil {
+		return []dict.Found{}, err
 	}
 
-	return results
+	s := solver.New(solver.MapStore{}, set)
+	return s.SearchDict(context.Background(), word, mode, maxDistance, property.AsInt(propSearchDictDefaultMaxResults))
 }
 
-func mapKeys(m map[string]bool) []string {
-	keys := make([]string, len(m))
+// runSearchDictCommand searches the word exactly or, given a mode and a distance, fuzzily
+func runSearchDictCommand(args []string) response {
+	if len(args) == 1 {
+		res, err := runSearchDict(args[0], property.AsInt(propSearchDictDefaultMaxResults))
+		if err != nil {
+			return errorResponse(err)
+		}
+		if len(res) == 0 {
+			return statusResponse(statusNotFound)
+		}
 
-	i := 0
-	for k := range m {
-		keys[i] = k
-		i++
+		tmp := make([]string, 0, len(res))
+		for k, v := range res {
+			tmp = append(tmp, k+": "+strconv.Itoa(v))
+		}
+		sort.Strings(tmp)
+		return newResponse(tmp)
 	}
-	return keys
-}
-
-func buildKubAssoc(input string) ([][]string, bool) {
-	kubParts := kubraya.SplitKubraya(input)
 
-	kubAssoc := make([][]string, len(kubParts))
-	for i, part := range kubParts {
-		kubAssoc[i] = runView(part)
-		if len(kubAssoc[i]) == 0 {
-			return [][]string{}, false
+	maxDistance := property.AsInt(propSearchDictDefaultDistance)
+	if len(args) > 2 {
+		num, err := strconv.Atoi(args[2])
+		if err != nil || num < 0 {
+			return statusResponse(statusBadRequest)
 		}
+		maxDistance = num
 	}
 
-	return kubAssoc, true
+	res, err := runSearchDictMode(args[0], args[1], maxDistance)
+	if err == dict.ErrUnknownMode {
+		return statusResponse(statusBadRequest)
+	}
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	tmp := make([]string, len(res))
+	for i, f := range res {
+		tmp[i] = f.Dict + ": " + strconv.Itoa(f.Line) + " " + f.Word + " (" + strconv.Itoa(f.Distance) + ")"
+	}
+	return newResponse(tmp)
 }
 
-func buildKubAssocComplete(input string) ([][]string, bool) {
+// buildKubAssocComplete gets the values of every part and tells if every part has some
+func buildKubAssocComplete(input string) ([][]string, bool, error) {
 	kubParts := kubraya.SplitKubraya(input)
 
 	complete := true
 	kubAssoc := make([][]string, len(kubParts))
 	for i, part := range kubParts {
-		kubAssoc[i] = runView(part)
+		vals, err := runView(part)
+		if err != nil {
+			return [][]string{}, false, err
+		}
+		kubAssoc[i] = vals
 		if len(kubAssoc[i]) == 0 {
 			complete = false
-			kubAssoc[i] = []string{}
 		}
 	}
 
-	return kubAssoc, complete
+	return kubAssoc, complete, nil
 }
 
-func combsOfKubraya(kubraya string) ([][]string, bool) {
-	if kubAssoc, ok := buildKubAssoc(kubraya); ok {
-		combs := combinations(kubAssoc)
-		return combs, true
+// newSolver gets a solver over the associations and the dicts of the current playbook
+func newSolver() (*solver.Solver, error) {
+	store, err := openDefaultStore()
+	if err != nil {
+		return nil, err
+	}
+	set, err := loadDictSet()
+	if err != nil {
+		return nil, err
 	}
 
-	return [][]string{}, false
+	return solver.New(store, set), nil
 }
 
-func runSolve(kubraya string) ([]string, bool) {
-	maxResults := property.AsInt(propSolveMaxResults)
-	results := make(map[string]bool)
+// solverOptions gets the solver options from the properties
+func solverOptions(maxResults int, cons solver.Constraint) solver.Options {
+	return solver.Options{
+		MaxResults:    maxResults,
+		UnknownMarker: property.AsString(propGuessUnknownMarker),
+		UnknownsLimit: property.AsInt(propGuessUnknownsLimit),
+		FuzzyEdits:    property.AsInt(propSolveFuzzyEdits),
+		BoundUnknowns: property.AsBool(propGuessBoundUnknowns),
+		Constraint:    cons,
+	}
+}
 
-	combs, ok := combsOfKubraya(kubraya)
-	if !ok {
-		return []string{}, false
+// solveCandidates solves the kubraya exactly keeping only the words that fit the constraint
+func solveCandidates(input string, cons solver.Constraint) ([]solver.Candidate, error) {
+	s, err := newSolver()
+	if err != nil {
+		return []solver.Candidate{}, err
 	}
-	for _, comb := range combs {
-		word := strings.Join(comb, "")
 
-		res := runSearchDict(word, 1)
-		if len(res) > 0 {
-			if !results[word] {
-				results[word] = true
-			}
+	opts := solverOptions(property.AsInt(propSolveMaxResults), cons)
+	opts.FuzzyEdits = 0
+	return s.Solve(context.Background(), input, opts)
+}
 
-			if len(results) == maxResults {
-				return mapKeys(results), true
-			}
-		}
+// fuzzySolveCandidates solves the kubraya allowing up to SolveFuzzyEdits edits where parts join.
+// Only solutions that need at least one edit and fit the constraint are returned
+func fuzzySolveCandidates(input string, cons solver.Constraint) ([]solver.Candidate, error) {
+	s, err := newSolver()
+	if err != nil {
+		return []solver.Candidate{}, err
+	}
+
+	return s.SolveFuzzy(context.Background(), input, solverOptions(property.AsInt(propSolveMaxResults), cons))
+}
+
+// guessCandidates solves the kubraya if every part is known, otherwise guesses it,
+// keeping only the words that fit the constraint
+func guessCandidates(input string, cons solver.Constraint) ([]solver.Candidate, error) {
+	_, complete, err := buildKubAssocComplete(input)
+	if err != nil {
+		return []solver.Candidate{}, err
 	}
-	if len(results) > 0 {
-		return mapKeys(results), true
+	if complete {
+		if res, err := solveCandidates(input, cons); !notFound(err) {
+			return res, err
+		}
 	}
 
-	return []string{}, false
+	s, err := newSolver()
+	if err != nil {
+		return []solver.Candidate{}, err
+	}
+	return s.Guess(context.Background(), input, solverOptions(property.AsInt(propGuessMaxResults), cons))
 }
 
-func allowUnknowns(kubAssoc [][]string) [][]string {
-	guessUnknownMarker := property.AsString(propGuessUnknownMarker)
+func runSolve(kubraya string) ([]string, error) {
+	cands, err := solveCandidates(kubraya, solver.Constraint{})
+	return solver.Words(cands), err
+}
 
-	for i, list := range kubAssoc {
-		if len(list) == 0 {
-			kubAssoc[i] = []string{guessUnknownMarker}
-		} else {
-			kubAssoc[i] = append(kubAssoc[i], guessUnknownMarker)
+func formatCandidate(c solver.Candidate) string {
+	res := c.Word
+	if c.Fuzzy() {
+		res = formatFuzzy(c)
+	}
+	if c.Guessed() && property.AsBool(propGuessExplainResults) {
+		inferred := make([]string, len(c.Inferred))
+		for i, p := range c.Inferred {
+			inferred[i] = buildAssocString(p.Key, []string{p.Val})
 		}
+		res = c.Pattern + " -> " + c.Word + " (" + strings.Join(inferred, ", ") + ")"
 	}
 
-	return kubAssoc
+	if property.AsBool(propShowScores) {
+		res += " [" + strconv.Itoa(c.Score) + "]"
+	}
+	return res
 }
 
-func wordGuessToRegexp(wordGuess string) string {
-	guessUnknownMarker := property.AsString(propGuessUnknownMarker)
+func runGuess(kubraya string) ([]string, error) {
+	cands, err := guessCandidates(kubraya, solver.Constraint{})
+	if err != nil {
+		return []string{}, err
+	}
 
-	return "^" + strings.ReplaceAll(wordGuess, guessUnknownMarker, ".+") + "$"
+	return formatGuess(cands), nil
 }
 
-func countValsInSlice(slc []string, val string) int {
-	counter := 0
-	for _, v := range slc {
-		if v == val {
-			counter++
-		}
+func formatGuess(cands []solver.Candidate) []string {
+	keys := make([]string, len(cands))
+	for i, c := range cands {
+		keys[i] = formatCandidate(c)
+	}
+	if len(cands) == 0 || !cands[0].Guessed() {
+		return keys
 	}
 
-	return counter
+	maxResults := property.AsInt(propGuessMaxResults)
+	if len(keys) >= maxResults {
+		keys = append(keys, "(First "+strconv.Itoa(maxResults)+" shown, more exist)")
+	}
+	return keys
 }
 
-func isCombGuessable(comb []string) bool {
-	guessUnknownMarker := property.AsString(propGuessUnknownMarker)
-	guessUnknownsLimit := property.AsInt(propGuessUnknownsLimit)
+// result tags used when solve falls back to guess
+const (
+	tagSolved  = "solved: "
+	tagGuessed = "guessed: "
+	tagFuzzy   = "fuzzy: "
+)
 
-	unk := countValsInSlice(comb, guessUnknownMarker)
+// runSolveCommand solves the kubraya, then tries fuzzy solve and, if SolveAutoGuess is on
+// and nothing is found, guesses it. A length or a mask may follow the kubraya
+func runSolveCommand(args []string) response {
+	input := args[0]
+	cons, rest, ok := parseConstraints(args[1:])
+	if !ok || len(rest) > 0 || !cons.HasParts(kubraya.SplitKubraya(input)) {
+		return statusResponse(statusBadRequest)
+	}
+	autoGuess := property.AsBool(propSolveAutoGuess)
 
-	// not really good. logic about guessability needs to be injected from params
-	// also maybe need smarter criteria - 3 of out 5 unknown is fine
-	// rely on percentage??
-	return unk <= guessUnknownsLimit && unk < len(comb)
-}
+	cands, err := solveCandidates(input, cons)
+	if notFound(err) {
+		cands, err = fuzzySolveCandidates(input, cons)
+	}
+	if notFound(err) && autoGuess {
+		cands, err = guessCandidates(input, cons)
+	}
+	if err != nil {
+		return errorResponse(err)
+	}
 
-func filterGuessableCombs(combs [][]string) [][]string {
-	res := [][]string{}
-	for _, comb := range combs {
-		if isCombGuessable(comb) {
-			res = append(res, comb)
+	learned := map[string][]string{}
+	if len(cands) == 1 {
+		if learned, err = runAutolearn(input, cands[0]); err != nil {
+			return errorResponse(err)
 		}
 	}
 
-	return res
-}
+	if !autoGuess {
+		res := make([]string, len(cands))
+		for i, c := range cands {
+			res[i] = formatCandidate(c)
+		}
+		return candidatesResponse(append(res, formatAssocChanges(tagLearned, learned)...), cands, learned)
+	}
 
-func countUnknowns(comb []string) int {
-	guessUnknownMarker := property.AsString(propGuessUnknownMarker)
-	return countValsInSlice(comb, guessUnknownMarker)
+	res := formatGuess(cands)
+	for i := range res {
+		switch {
+		case i >= len(cands):
+		case cands[i].Guessed():
+			res[i] = tagGuessed + res[i]
+		case cands[i].Fuzzy():
+			res[i] = tagFuzzy + res[i]
+		default:
+			res[i] = tagSolved + res[i]
+		}
+	}
+	return candidatesResponse(append(res, formatAssocChanges(tagLearned, learned)...), cands, learned)
 }
 
-func sortCombsByBestChances(combs [][]string) [][]string {
-	sort.Slice(combs, func(i, j int) bool {
-		return countUnknowns(combs[i]) < countUnknowns(combs[j])
-	})
+// candidatesResponse makes a response with the candidate words as results
+// and the associations the verb changed
+func candidatesResponse(lines []string, cands []solver.Candidate, assoc map[string][]string) response {
+	r := newResponse(lines)
+	r.Results = solver.Words(cands)
+	r.Explanations = make([]string, len(cands))
+	for i, c := range cands {
+		r.Explanations[i] = formatCandidate(c)
+	}
+	if len(assoc) > 0 {
+		r.Associations = assoc
+	}
 
-	return combs
+	return r
 }
 
-func countUnknownsInWord(word string) int {
-	guessUnknownMarker := property.AsString(propGuessUnknownMarker)
-	return strings.Count(word, guessUnknownMarker)
-}
+// runGuessCommand guesses the kubraya and learns from a single answer.
+// With an answer given, it adds the associations inferred for that answer instead.
+// A length or a mask may follow the kubraya
+func runGuessCommand(args []string) response {
+	cons, rest, ok := parseConstraints(args[1:])
+	if !ok || len(rest) > 1 || !cons.HasParts(kubraya.SplitKubraya(args[0])) {
+		return statusResponse(statusBadRequest)
+	}
 
-func sortByUnknownsInWord(words []string) []string {
-	sort.Slice(words, func(i, j int) bool {
-		return countUnknownsInWord(words[i]) < countUnknownsInWord(words[j])
-	})
-	return words
+	cands, err := guessCandidates(args[0], cons)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	if len(rest) > 0 {
+		for _, c := range cands {
+			if c.Word == rest[0] {
+				added, err := runAddInferred(c)
+				if err != nil {
+					return errorResponse(err)
+				}
+				res := []string{formatCandidate(c)}
+				return candidatesResponse(append(res, formatAssocChanges(tagAdded, added)...), []solver.Candidate{c}, added)
+			}
+		}
+		return statusResponse(statusNotFound)
+	}
+
+	learned := map[string][]string{}
+	if len(cands) == 1 {
+		if learned, err = runAutolearn(args[0], cands[0]); err != nil {
+			return errorResponse(err)
+		}
+	}
+	return candidatesResponse(append(formatGuess(cands), formatAssocChanges(tagLearned, learned)...), cands, learned)
 }
 
-func runGuess(kubraya string) ([]string, bool) {
-	kubAssoc, complete := buildKubAssocComplete(kubraya)
-	if complete {
-		if res, ok := runSolve(kubraya); ok {
-			return res, true
+// runAddInferred adds the associations inferred by a guess
+func runAddInferred(c solver.Candidate) (map[string][]string, error) {
+	res := map[string][]string{}
+	for _, p := range c.Inferred {
+		added, err := runSmartAdd(p.Key, p.Val)
+		if err != nil {
+			return res, err
+		}
+		for k, v := range added {
+			res[k] = v
 		}
 	}
 
-	kubAssoc = allowUnknowns(kubAssoc)
-	combs := combinations(kubAssoc)
-	combs = filterGuessableCombs(combs)
-	combs = sortCombsByBestChances(combs)
+	return res, nil
+}
 
-	maxResults := property.AsInt(propGuessMaxResults)
-	results := make(map[string]bool)
-	explains := make(map[string]string)
-	guessExplainResults := property.AsBool(propGuessExplainResults)
-	for _, comb := range combs {
-		wordGuess := strings.Join(comb, "")
-		wordRegexp := wordGuessToRegexp(wordGuess)
-		re := regexp.MustCompile(wordRegexp)
-
-		words := searchDictByRegexpGetWords(re, maxResults)
-		if len(words) > 0 {
-			for _, word := range words {
-				if !results[word] {
-					if guessExplainResults {
-						explains[word] = wordGuess
-					}
-					results[word] = true
-				}
-				if len(results) == maxResults {
-					break
-				}
-			}
+// runAutolearn adds the associations the solution relies on when SolveAutolearn is on
+// and returns the ones that are new. Solutions that would teach more than SolveAutolearnStep
+// new associations are not learned
+func runAutolearn(input string, c solver.Candidate) (map[string][]string, error) {
+	if !property.AsBool(propSolveAutolearn) {
+		return map[string][]string{}, nil
+	}
+
+	parts := kubraya.SplitKubraya(input)
+	if len(parts) != len(c.Chunks) {
+		return map[string][]string{}, nil
+	}
+
+	newPairs := 0
+	for i, part := range parts {
+		vals, err := runView(part)
+		if err != nil {
+			return map[string][]string{}, err
 		}
-		if len(results) == maxResults {
-			break
+		if findStringInSlice(c.Chunks[i], vals) == -1 {
+			newPairs++
 		}
 	}
+	if newPairs == 0 || newPairs > property.AsInt(propSolveAutolearnStep) {
+		return map[string][]string{}, nil
+	}
+
+	store, err := openDefaultStore()
+	if err != nil {
+		return map[string][]string{}, err
+	}
+	before := store.Snapshot()
+	added, err := runAddSolution(input, strings.Join(c.Chunks, kubraya.KubrayaSeparator))
+	if err != nil {
+		return map[string][]string{}, err
+	}
+
+	return newValues(before, added), nil
+}
 
-	if len(results) > 0 {
-		keys := mapKeys(results)
-		if guessExplainResults {
-			for i, key := range keys {
-				keys[i] = explains[key] + " -> " + key
+// newValues keeps the values of assoc that were not in before, by key
+func newValues(before, assoc map[string][]string) map[string][]string {
+	res := map[string][]string{}
+	for k, vals := range assoc {
+		for _, v := range vals {
+			if findStringInSlice(v, before[k]) == -1 {
+				res[k] = append(res[k], v)
 			}
-			keys = sortByUnknownsInWord(keys)
 		}
-		if len(keys) >= maxResults {
-			keys = append(keys, "(First "+strconv.Itoa(maxResults)+" shown, more exist)")
-		}
-		return keys, true
 	}
 
-	return []string{}, false
+	return res
+}
+
+// association change tags
+const (
+	tagAdded   = "added: "
+	tagLearned = "learned: "
+)
+
+func formatAssocChanges(tag string, assoc map[string][]string) []string {
+	keys := make([]string, 0, len(assoc))
+	for k := range assoc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	res := make([]string, len(keys))
+	for i, k := range keys {
+		res[i] = tag + buildAssocString(k, assoc[k])
+	}
+	return res
 }
 
-func runListPlaybooks() []string {
+func runListPlaybooks() ([]string, error) {
 	playbooksDir := property.AsString(propPlaybooksDir)
 	playbookCurrent := property.AsString(propPlaybookCurrent)
 
 	res := []string{}
 	files, err := ioutil.ReadDir(playbooksDir)
-	checkError(err)
+	if err != nil {
+		return res, err
+	}
 	for _, f := range files {
 		if !f.IsDir() {
 			continue
@@ -741,7 +931,7 @@ func runListPlaybooks() []string {
 		res = append(res, ff+f.Name())
 	}
 
-	return res
+	return res, nil
 }
 
 func findStringInSlice(str string, strings []string) int {
@@ -753,61 +943,125 @@ func findStringInSlice(str string, strings []string) int {
 	return -1
 }
 
+// minArgs is the number of arguments a verb can't work without
+var minArgs = map[string]int{
+	vAdd:         2,
+	vAddBoth:     2,
+	vAddSolution: 2,
+	vBatch:       1,
+	vCheck:       2,
+	vCompose:     1,
+	vGuess:       1,
+	vHint:        1,
+	vRemove:      2,
+	vRemoveBoth:  2,
+	vSearchDict:  1,
+	vSolve:       1,
+	vView:        1,
+}
+
 func runCommand(verb string, args []string) string {
+	return runVerb(verb, args).text()
+}
+
+// assocResponse makes a response of the associations the verb changed
+func assocResponse(assoc map[string][]string, err error) response {
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	res := []string{}
+	for k, v := range assoc {
+		res = append(res, buildAssocString(k, v))
+	}
+
+	r := newResponse(res)
+	r.Associations = assoc
+	return r
+}
+
+// assocPairResponse makes a response of two associations the verb changed, in that order
+func assocPairResponse(a, b string, res [2][]string, err error) response {
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	r := newResponse([]string{buildAssocString(a, res[0]), buildAssocString(b, res[1])})
+	r.Associations = map[string][]string{a: res[0], b: res[1]}
+	return r
+}
+
+func runVerb(verb string, args []string) response {
+	beginCommand()
+	defer endCommand()
+
+	if len(args) < minArgs[verb] {
+		return statusResponse(statusBadRequest)
+	}
+
 	switch verb {
 	case vAdd:
-		tmp := runSmartAdd(args[0], args[1])
-		res := []string{}
-		for k, v := range tmp {
-			res = append(res, buildAssocString(k, v))
-		}
-		return strings.Join(res, "\n")
+		return assocResponse(runSmartAdd(args[0], args[1]))
 	case vAddBoth:
-		res := runAddBoth(args[0], args[1])
-		return buildAssocString(args[0], res[0]) + "\n" + buildAssocString(args[1], res[1])
+		res, err := runAddBoth(args[0], args[1])
+		return assocPairResponse(args[0], args[1], res, err)
 	case vAddSolution:
-		tmp := runAddSolution(args[0], args[1])
-		res := []string{}
-		for k, v := range tmp {
-			res = append(res, buildAssocString(k, v))
+		return assocResponse(runAddSolution(args[0], args[1]))
+	case vBatch:
+		res, err := runBatch(args[0])
+		if err != nil {
+			return errorResponse(err)
 		}
-		return strings.Join(res, "\n")
-	case vGuess:
-		if res, ok := runGuess(args[0]); ok {
-			return strings.Join(res, "\n")
+		r := newResponse(res.lines())
+		r.Results = res
+		return r
+	case vCheck:
+		res, err := runCheck(args[0], args[1])
+		if err != nil {
+			return errorResponse(err)
 		}
-		return "404 NOT FOUND"
+		r := newResponse(res.lines())
+		r.Results = res
+		return r
+	case vCompose:
+		return runComposeCommand(args[0])
+	case vGuess:
+		return runGuessCommand(args)
 	case vRemove:
-		res := runRemove(args[0], args[1])
-		return buildAssocString(args[0], res)
+		res, err := runRemove(args[0], args[1])
+		if err != nil {
+			return errorResponse(err)
+		}
+		r := newResponse([]string{buildAssocString(args[0], res)})
+		r.Associations = map[string][]string{args[0]: res}
+		return r
 	case vRemoveBoth:
-		res := runRemoveBoth(args[0], args[1])
-		return buildAssocString(args[0], res[0]) + "\n" + buildAssocString(args[1], res[1])
+		res, err := runRemoveBoth(args[0], args[1])
+		return assocPairResponse(args[0], args[1], res, err)
+	case vHint:
+		return runHintCommand(args)
+	case vPlay:
+		return newResponse([]string{runPlay()})
 	case vPlaybook:
-		res := runListPlaybooks()
-		return strings.Join(res, "\n")
+		return runPlaybookCommand(args)
 	case vSearchDict:
-		res := runSearchDict(args[0], property.AsInt(propSearchDictDefaultMaxResults))
-		if len(res) == 0 {
-			return "404 NOT FOUND"
-		}
-
-		tmp := make([]string, 0, len(res))
-		for k, v := range res {
-			tmp = append(tmp, k+": "+strconv.Itoa(v))
-		}
-		return strings.Join(tmp, "\n")
+		return runSearchDictCommand(args)
+	case vNormalize:
+		return runNormalizeCommand(args)
 	case vSolve:
-		if res, ok := runSolve(args[0]); ok {
-			return strings.Join(res, "\n")
-		}
-		return "404 NOT FOUND"
+		return runSolveCommand(args)
+	case vUndo:
+		return runUndoCommand(args)
 	case vView:
-		res := runView(args[0])
-		return buildAssocString(args[0], res)
+		res, err := runView(args[0])
+		if err != nil {
+			return errorResponse(err)
+		}
+		r := newResponse([]string{buildAssocString(args[0], res)})
+		r.Results = res
+		return r
 	default:
-		msg := fmt.Sprintf("501 NOT IMPLEMENTED\n%s", verb)
-		return msg
+		return statusResponse(statusNotImplemented, verb)
 	}
 }
 
@@ -845,13 +1099,18 @@ func autoDetectPropertiesPath() string {
 func main() {
 	property.PropertiesPath = autoDetectPropertiesPath()
 
-	verb := parseVerb(os.Args[1:])
-	if verb == "" {
-		answer := "400 BAD REQUEST"
-		fmt.Println(answer)
-	} else {
-		args := extractArgs(verb, os.Args[1:])
-		answer := runCommand(verb, args)
-		fmt.Println(answer)
+	format, osArgs, ok := extractFormat(os.Args[1:])
+	verb := parseVerb(osArgs)
+	if verb == "" || !ok {
+		answer := statusResponse(statusBadRequest)
+		fmt.Println(answer.render(format))
+		return
+	}
+
+	args := extractArgs(verb, osArgs)
+	answer := runVerb(verb, args)
+	fmt.Println(answer.render(format))
+	if code := exitCode(verb, answer); code != 0 {
+		os.Exit(code)
 	}
 }
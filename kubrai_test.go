@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"io/ioutil"
 	"os"
 	"reflect"
 	"regexp"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/ruslanbes/kubrai/fileutils"
 	"github.com/ruslanbes/kubrai/property"
+	"github.com/ruslanbes/kubrai/solver"
 )
 
 func setUpTestProperties(props map[string]string) {
@@ -18,6 +21,9 @@ func setUpTestProperties(props map[string]string) {
 
 	property.PropertiesPath = testPropertyDir
 	property.SetProperties(props)
+
+	dictSets = map[string]loadedDictSet{}
+	closeStores()
 }
 
 func Test_findExactVerb(t *testing.T) {
@@ -233,17 +239,34 @@ func Test_saveAndLoadAssoc(t *testing.T) {
 	assocFile := getCurrentPlaybookDir() + "/associations/associationsTest.txt"
 	assoc := make(map[string][]string)
 	assoc["aaa"] = []string{"bbb", "ccc"}
+	assoc["time:out"] = []string{"at&t, inc", `c:\`}
 
-	saveAssoc(assocFile, assoc)
-	got := loadAssoc(assocFile)
+	if err := saveAssoc(assocFile, assoc); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readAssoc(assocFile)
 
-	if !reflect.DeepEqual(assoc, got) {
-		t.Errorf("loadAssoc() = %v, want %v", got, assoc)
+	if err != nil || !reflect.DeepEqual(assoc, got) {
+		t.Errorf("readAssoc() = %v, %v, want %v", got, err, assoc)
 	}
 
 	//cleanup
-	err := os.Remove(assocFile)
-	checkError(err)
+	if err := os.Remove(assocFile); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_openDefaultStore_missingPlaybook(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propPlaybookCurrent: "missing",
+		propPlaybooksDir:    "./test/data/playbooks",
+	})
+
+	_, err := openDefaultStore()
+	want := &playbookError{"missing"}
+	if !reflect.DeepEqual(err, want) {
+		t.Errorf("openDefaultStore() error = %v, want %v", err, want)
+	}
 }
 
 func Test_addBeforeFirstLonger(t *testing.T) {
@@ -364,8 +387,8 @@ func Test_runAdd(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := runAdd(tt.args.a, tt.args.b); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("runAdd() = %v, want %v", got, tt.want)
+			if got, err := runAdd(tt.args.a, tt.args.b); err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runAdd() = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
@@ -418,8 +441,8 @@ func Test_runAddBoth(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := runAddBoth(tt.args.a, tt.args.b); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("runAddBoth() = %v, want %v", got, tt.want)
+			if got, err := runAddBoth(tt.args.a, tt.args.b); err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runAddBoth() = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
@@ -493,9 +516,9 @@ func Test_runSmartAdd(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := runSmartAdd(tt.args.a, tt.args.b)
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("runSmartAdd() got = %v, want %v", got, tt.want)
+			got, err := runSmartAdd(tt.args.a, tt.args.b)
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runSmartAdd() got = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
@@ -607,8 +630,8 @@ func Test_runRemove(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := runRemove(tt.args.a, tt.args.b); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("runRemove() = %v, want %v", got, tt.want)
+			if got, err := runRemove(tt.args.a, tt.args.b); err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runRemove() = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
@@ -660,8 +683,8 @@ func Test_runRemoveBoth(t *testing.T) {
 		}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := runRemoveBoth(tt.args.a, tt.args.b); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("runRemoveBoth() = %v, want %v", got, tt.want)
+			if got, err := runRemoveBoth(tt.args.a, tt.args.b); err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runRemoveBoth() = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
@@ -707,8 +730,8 @@ func Test_runView(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := runView(tt.args.a); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("runView() = %v, want %v", got, tt.want)
+			if got, err := runView(tt.args.a); err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runView() = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
@@ -736,114 +759,19 @@ func Test_loadDicts(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := loadDicts(); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("loadDicts() = %v, want %v", got, tt.want)
+			if got, err := loadDicts(); err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadDicts() = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
-}
 
-func Test_nextMultiDimValue(t *testing.T) {
-	type args struct {
-		counter    []int
-		maxCounter []int
-	}
-	tests := []struct {
-		name  string
-		args  args
-		want  []int
-		want1 bool
-	}{
-		{
-			name:  "Test000",
-			args:  args{[]int{0, 0, 0}, []int{1, 2, 3}},
-			want:  []int{1, 0, 0},
-			want1: true,
-		},
-		{
-			name:  "Test100",
-			args:  args{[]int{1, 0, 0}, []int{1, 2, 3}},
-			want:  []int{0, 1, 0},
-			want1: true,
-		},
-		{
-			name:  "Test010",
-			args:  args{[]int{0, 1, 0}, []int{1, 2, 3}},
-			want:  []int{1, 1, 0},
-			want1: true,
-		},
-		{
-			name:  "Test110",
-			args:  args{[]int{1, 1, 0}, []int{1, 2, 3}},
-			want:  []int{0, 2, 0},
-			want1: true,
-		},
-		{
-			name:  "Test020",
-			args:  args{[]int{0, 2, 0}, []int{1, 2, 3}},
-			want:  []int{1, 2, 0},
-			want1: true,
-		},
-		{
-			name:  "Test120",
-			args:  args{[]int{1, 2, 0}, []int{1, 2, 3}},
-			want:  []int{0, 0, 1},
-			want1: true,
-		},
-		{
-			name:  "Test003",
-			args:  args{[]int{0, 0, 3}, []int{1, 2, 3}},
-			want:  []int{1, 0, 3},
-			want1: true,
-		},
-		{
-			name:  "Test123",
-			args:  args{[]int{1, 2, 3}, []int{1, 2, 3}},
-			want:  []int{0, 0, 0},
-			want1: false,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, got1 := nextMultiDimValue(tt.args.counter, tt.args.maxCounter)
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("nextMultiDimValue() got = %v, want %v", got, tt.want)
-			}
-			if got1 != tt.want1 {
-				t.Errorf("nextMultiDimValue() got1 = %v, want %v", got1, tt.want1)
-			}
-		})
-	}
-}
+	// a directory can be opened but not read
+	os.Mkdir(dictsDir+"/broken.test", 0755)
+	defer os.Remove(dictsDir + "/broken.test")
 
-func Test_combinations(t *testing.T) {
-	type args struct {
-		items [][]string
-	}
-	tests := []struct {
-		name string
-		args args
-		want [][]string
-	}{
-		{
-			name: "Test1",
-			args: args{[][]string{{"a", "b", "c"}, {"1", "2"}, {"TRUE"}, {"?"}}},
-			want: [][]string{
-				{"a", "1", "TRUE", "?"},
-				{"b", "1", "TRUE", "?"},
-				{"c", "1", "TRUE", "?"},
-				{"a", "2", "TRUE", "?"},
-				{"b", "2", "TRUE", "?"},
-				{"c", "2", "TRUE", "?"},
-			},
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := combinations(tt.args.items); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("combinations() = %v, want %v", got, tt.want)
-			}
-		})
+	var dictErr *dictError
+	if _, err := loadDicts(); !errors.As(err, &dictErr) || dictErr.dict != "broken.test" {
+		t.Errorf("loadDicts() error = %v, want a dictError of broken.test", err)
 	}
 }
 
@@ -946,20 +874,20 @@ func Test_runSolve(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, got1 := runSolve(tt.args.kubraya)
+			got, err := runSolve(tt.args.kubraya)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("runSolve() got = %v, want %v", got, tt.want)
 			}
-			if got1 != tt.want1 {
+			if got1 := err == nil; got1 != tt.want1 {
 				t.Errorf("runSolve() got1 = %v, want %v", got1, tt.want1)
 			}
 		})
 	}
 
 	t.Run("limit", func(t *testing.T) {
-		got, got1 := runSolve("max_min")
-		if !got1 {
-			t.Errorf("runSolve() got1 = %v, want %v", got1, true)
+		got, err := runSolve("max_min")
+		if err != nil {
+			t.Errorf("runSolve() error = %v, want %v", err, nil)
 		}
 
 		sliceSize := 3
@@ -1030,14 +958,14 @@ func Test_runSearchDict(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := runSearchDict(tt.args.word, tt.args.maxResults); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("runSearchDict() = %v, want %v", got, tt.want)
+			if got, err := runSearchDict(tt.args.word, tt.args.maxResults); err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runSearchDict() = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
 
 	// test limit. Result is random
-	got := runSearchDict("wordb", 1)
+	got, _ := runSearchDict("wordb", 1)
 	if val, ok := got["dict.test"]; ok && val != 1 {
 		t.Errorf("runSearchDict() = %v, test limit failed", got)
 	} else if val, ok := got["oddDict.test"]; ok && val != 0 {
@@ -1046,108 +974,6 @@ func Test_runSearchDict(t *testing.T) {
 
 }
 
-func Test_allowUnknowns(t *testing.T) {
-	setUpTestProperties(map[string]string{
-		propGuessUnknownMarker: "???",
-	})
-
-	type args struct {
-		kubAssoc [][]string
-	}
-	tests := []struct {
-		name string
-		args args
-		want [][]string
-	}{
-		{
-			name: "Test1",
-			args: args{[][]string{{"one", "two"}, {}, {"a", "b", "c"}}},
-			want: [][]string{{"one", "two", "???"}, {"???"}, {"a", "b", "c", "???"}},
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := allowUnknowns(tt.args.kubAssoc); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("allowUnknowns() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func Test_searchDictByRegexpGetWords(t *testing.T) {
-	setUpTestProperties(map[string]string{
-		propPlaybookCurrent: "default",
-		propPlaybooksDir:    "./test/data/playbooks",
-		propDictsExt:        ".test",
-	})
-
-	dictsDir := getFullDictsDir()
-	fileutils.FilePutContents(dictsDir+"/"+"dict.test", strings.Join(
-		[]string{
-			"aabbcc",
-			"abc",
-			"bcd",
-			"def",
-			"папа",
-			"мама",
-			"брат",
-		}, "\n"))
-	defer fileutils.FileRemove(dictsDir + "/" + "dict.test")
-
-	type args struct {
-		re         *regexp.Regexp
-		maxResults int
-	}
-	tests := []struct {
-		name string
-		args args
-		want []string
-	}{
-		{
-			name: "Test0",
-			args: args{regexp.MustCompile("aa.+cc"), 0},
-			want: []string{},
-		},
-		{
-			name: "Test1",
-			args: args{regexp.MustCompile("aa.+cc"), 50},
-			want: []string{"aabbcc"},
-		},
-		{
-			name: "Test2",
-			args: args{regexp.MustCompile("a.+.+"), 50},
-			want: []string{"aabbcc", "abc"},
-		},
-		{
-			name: "TestCyrillic1",
-			args: args{regexp.MustCompile("па.+"), 50},
-			want: []string{"папа"},
-		},
-		{
-			name: "TestCyrillic2",
-			args: args{regexp.MustCompile(".+па.+"), 50},
-			want: []string{},
-		},
-		{
-			name: "TestCyrillic3",
-			args: args{regexp.MustCompile(".+а.+"), 50},
-			want: []string{"папа", "мама", "брат"},
-		},
-		{
-			name: "TestCyrillic4",
-			args: args{regexp.MustCompile(".+а.+"), 2},
-			want: []string{"папа", "мама"},
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := searchDictByRegexpGetWords(tt.args.re, tt.args.maxResults); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("searchDictByRegexpGetWords() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func Test_runGuess(t *testing.T) {
 	setUpTestProperties(map[string]string{
 		propAssocFileKeySeparator: ":",
@@ -1236,11 +1062,11 @@ func Test_runGuess(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, got1 := runGuess(tt.args.kubraya)
+			got, err := runGuess(tt.args.kubraya)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("runGuess() got = %v, want %v", got, tt.want)
 			}
-			if got1 != tt.want1 {
+			if got1 := err == nil; got1 != tt.want1 {
 				t.Errorf("runGuess() got1 = %v, want %v", got1, tt.want1)
 			}
 		})
@@ -1259,83 +1085,410 @@ func Test_runGuess(t *testing.T) {
 
 }
 
-func Test_countUnknownsInWord(t *testing.T) {
-	setUpTestProperties(map[string]string{
-		propGuessUnknownMarker: "???",
-	})
+func Test_getPossibleVerbs(t *testing.T) {
+	got := getPossibleVerbs()
+
+	foundSolve := false
+	re := regexp.MustCompile("^[A-Za-z]+$")
+	for _, v := range got {
+		if v == "solve" {
+			foundSolve = true
+		}
+
+		if !re.MatchString(v) {
+			t.Errorf("getPossibleVerbs() verb should avoid using special chars: %s", v)
+		}
+	}
+
+	if !foundSolve {
+		t.Errorf("getPossibleVerbs() doesn't contain solve")
+	}
+}
+
+func Test_runSolveCommand(t *testing.T) {
+	props := map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propGuessExplainResults:   "ON",
+		propGuessMaxResults:       "50",
+		propGuessUnknownsLimit:    "2",
+		propGuessUnknownMarker:    "???",
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+		propDictsExt:              ".test",
+		propSolveFuzzyEdits:       "1",
+		propSolveMaxResults:       "5",
+	}
+	setUpTestProperties(props)
+
+	dictsDir := getFullDictsDir()
+	fileutils.FilePutContents(dictsDir+"/"+"dict.test", strings.Join(
+		[]string{
+			"boycott",
+			"copy",
+			"ice cream",
+		}, "\n"))
+	defer fileutils.FileRemove(dictsDir + "/" + "dict.test")
+
+	assoc := make(map[string][]string)
+	assoc["policeman"] = []string{"cop"}
+	assoc["pie"] = []string{"py"}
+	assoc["frozen"] = []string{"ice"}
+	assoc["milk"] = []string{"cream"}
+	assoc["girl"] = []string{"boy"}
+	assoc["bed"] = []string{"cot"}
+	assoc["tea"] = []string{"t"}
+	saveDefaultAssoc(assoc)
 
 	type args struct {
-		word string
+		kubraya   string
+		autoGuess string
 	}
 	tests := []struct {
 		name string
 		args args
-		want int
+		want string
 	}{
 		{
-			name: "Test01",
-			args: args{"??????ан"},
-			want: 2,
+			name: "SolvedOff",
+			args: args{"girl_bed_tea", "OFF"},
+			want: "boycott",
 		},
 		{
-			name: "Test02",
-			args: args{"???триант"},
-			want: 1,
+			name: "NotSolvedOff",
+			args: args{"policeman_why", "OFF"},
+			want: "404 NOT FOUND",
+		},
+		{
+			name: "SolvedOn",
+			args: args{"girl_bed_tea", "ON"},
+			want: "solved: boycott",
+		},
+		{
+			name: "GuessedOn",
+			args: args{"policeman_why", "ON"},
+			want: "guessed: cop??? -> copy (why:y)",
+		},
+		{
+			name: "Phrase",
+			args: args{"frozen_milk", "OFF"},
+			want: "ice cream",
+		},
+		{
+			name: "GuessedPhrase",
+			args: args{"frozen_why", "ON"},
+			want: "guessed: ice??? -> ice cream (why:cream)",
+		},
+		{
+			name: "FuzzyOff",
+			args: args{"policeman_pie", "OFF"},
+			want: "cop+py -> copy (merged p of cop and py)",
+		},
+		{
+			name: "FuzzyOn",
+			args: args{"policeman_pie", "ON"},
+			want: "fuzzy: cop+py -> copy (merged p of cop and py)",
+		},
+		{
+			name: "NotGuessedOn",
+			args: args{"why_not", "ON"},
+			want: "404 NOT FOUND",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := countUnknownsInWord(tt.args.word); got != tt.want {
-				t.Errorf("countUnknownsInWord() = %v, want %v", got, tt.want)
+			props[propSolveAutoGuess] = tt.args.autoGuess
+			property.SetProperties(props)
+			if got := runSolveCommand([]string{tt.args.kubraya}).text(); got != tt.want {
+				t.Errorf("runSolveCommand() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func Test_sortByUnknownsInWord(t *testing.T) {
-	setUpTestProperties(map[string]string{
-		propGuessUnknownMarker: "???",
-	})
+func Test_runAutolearn(t *testing.T) {
+	props := map[string]string{
+		propAddAutoBothMaxlen:     "0",
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+		propSolveAutolearn:        "ON",
+		propSolveAutolearnStep:    "1",
+	}
+	setUpTestProperties(props)
+
+	saveDefaultAssoc(map[string][]string{"policeman": {"cop"}, "girl": {"boy"}})
 
 	type args struct {
-		words []string
+		kubraya string
+		c       solver.Candidate
 	}
 	tests := []struct {
-		name string
-		args args
-		want []string
+		name      string
+		args      args
+		autolearn string
+		want      map[string][]string
 	}{
 		{
-			name: "Test01",
-			args: args{[]string{"??????ан -> аргирофан", "??????ан -> аркан", "???триант -> репатриант", "??????ан -> Атлантический океан", "???триант -> экспатриант"}},
-			want: []string{"???триант -> репатриант", "???триант -> экспатриант", "??????ан -> аргирофан", "??????ан -> аркан", "??????ан -> Атлантический океан"},
+			name:      "Off",
+			args:      args{"policeman_why", solver.Candidate{Word: "copy", Chunks: []string{"cop", "y"}}},
+			autolearn: "OFF",
+			want:      map[string][]string{},
+		},
+		{
+			name:      "TooManyNew",
+			args:      args{"girl_bed_tea", solver.Candidate{Word: "boycott", Chunks: []string{"boy", "cot", "t"}}},
+			autolearn: "ON",
+			want:      map[string][]string{},
+		},
+		{
+			name:      "PartCountMismatch",
+			args:      args{"policeman_why", solver.Candidate{Word: "copy", Chunks: []string{"copy"}}},
+			autolearn: "ON",
+			want:      map[string][]string{},
+		},
+		{
+			name:      "OneNew",
+			args:      args{"policeman_why", solver.Candidate{Word: "copy", Chunks: []string{"cop", "y"}}},
+			autolearn: "ON",
+			want:      map[string][]string{"why": {"y"}},
+		},
+		{
+			name:      "NewValueOfKnownKey",
+			args:      args{"girl_why", solver.Candidate{Word: "lassy", Chunks: []string{"lass", "y"}}},
+			autolearn: "ON",
+			want:      map[string][]string{"girl": {"lass"}},
+		},
+		{
+			name:      "NothingNew",
+			args:      args{"policeman_why", solver.Candidate{Word: "copy", Chunks: []string{"cop", "y"}}},
+			autolearn: "ON",
+			want:      map[string][]string{},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := sortByUnknownsInWord(tt.args.words); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("sortByUnknownsInWord() = %v, want %v", got, tt.want)
+			props[propSolveAutolearn] = tt.autolearn
+			property.SetProperties(props)
+			if got, err := runAutolearn(tt.args.kubraya, tt.args.c); err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runAutolearn() = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
 }
 
-func Test_getPossibleVerbs(t *testing.T) {
-	got := getPossibleVerbs()
+// Test_readmeExamples answers the README examples with the shipped properties and default playbook
+func Test_readmeExamples(t *testing.T) {
+	props := map[string]string{}
+	files, err := ioutil.ReadDir("./property/properties")
+	if err != nil {
+		t.Fatalf("read shipped properties: %v", err)
+	}
+	for _, f := range files {
+		val, _ := ioutil.ReadFile("./property/properties/" + f.Name())
+		props[f.Name()] = string(val)
+	}
+	props[propPlaybookCurrent] = "readme"
+	props[propPlaybooksDir] = "./test/data/playbooks"
+	setUpTestProperties(props)
 
-	foundSolve := false
-	re := regexp.MustCompile("^[A-Za-z]+$")
-	for _, v := range got {
-		if v == "solve" {
-			foundSolve = true
-		}
+	playbookDir := getCurrentPlaybookDir()
+	if err := fileutils.CopyDir("./playbook/playbooks/default", playbookDir); err != nil {
+		t.Fatalf("copy default playbook: %v", err)
+	}
+	defer os.RemoveAll(playbookDir)
 
-		if !re.MatchString(v) {
-			t.Errorf("getPossibleVerbs() verb should avoid using special chars: %s", v)
-		}
+	tests := []struct {
+		name string
+		verb string
+		args []string
+		want string
+	}{
+		{"Solve", vSolve, []string{"policeman_question"}, "copy"},
+		{"SolveGuessing", vSolve, []string{"girl_bed_tea"}, "boycott"},
+		{"SolveWithSymbols", vSolve, []string{"period_out_&T_and"}, "terminator"},
+		{"SolveFourParts", vSolve, []string{"amateur_psi_6_thanks"}, "proximity"},
+		{"Guess", vGuess, []string{"policeman_why"}, "copy"},
+		{"GuessLength", vGuess, []string{"policeman_why", "4"}, "copy"},
+		{"GuessMask", vGuess, []string{"policeman_why", "c??y"}, "copy"},
 	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := runVerb(tt.verb, extractArgs(tt.verb, tt.args))
+			if got, ok := r.Results.([]string); r.Status != statusOK || !ok || len(got) == 0 || got[0] != tt.want {
+				t.Errorf("runVerb() = %v, want %v first", r.text(), tt.want)
+			}
+		})
+	}
+}
 
-	if !foundSolve {
-		t.Errorf("getPossibleVerbs() doesn't contain solve")
+func Test_runGuessCommand(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAddAutoBothMaxlen:     "3",
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propGuessExplainResults:   "ON",
+		propGuessMaxResults:       "50",
+		propGuessUnknownsLimit:    "2",
+		propGuessUnknownMarker:    "???",
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+		propDictsExt:              ".test",
+		propSolveAutolearn:        "OFF",
+		propSolveMaxResults:       "5",
+	})
+
+	dictsDir := getFullDictsDir()
+	fileutils.FilePutContents(dictsDir+"/"+"dict.test", strings.Join(
+		[]string{
+			"cope",
+			"copy\t1000",
+		}, "\n"))
+	defer fileutils.FileRemove(dictsDir + "/" + "dict.test")
+
+	saveDefaultAssoc(map[string][]string{"policeman": {"cop"}})
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "Guess",
+			args: []string{"policeman_why"},
+			want: "cop??? -> copy (why:y)\ncop??? -> cope (why:e)",
+		},
+		{
+			name: "Mask",
+			args: []string{"policeman_why", "c??e"},
+			want: "cop??? -> cope (why:e)",
+		},
+		{
+			name: "Length",
+			args: []string{"policeman_why", "5"},
+			want: "404 NOT FOUND",
+		},
+		{
+			name: "BoundOverride",
+			args: []string{"policeman_why", "why:2"},
+			want: "404 NOT FOUND",
+		},
+		{
+			name: "BoundTooWide",
+			args: []string{"policeman_why", "why:1-1001"},
+			want: "400 BAD REQUEST",
+		},
+		{
+			name: "BoundOverrideOfOtherPart",
+			args: []string{"policeman_why", "what:1"},
+			want: "400 BAD REQUEST",
+		},
+		{
+			name: "ConflictingConstraints",
+			args: []string{"policeman_why", "4", "c???y"},
+			want: "400 BAD REQUEST",
+		},
+		{
+			name: "AddWrongAnswer",
+			args: []string{"policeman_why", "cops"},
+			want: "404 NOT FOUND",
+		},
+		{
+			name: "AddAnswer",
+			args: []string{"policeman_why", "copy"},
+			want: "cop??? -> copy (why:y)\nadded: why:y\nadded: y:why",
+		},
+		{
+			name: "Solved",
+			args: []string{"policeman_why"},
+			want: "copy",
+		},
+		{
+			name: "SolvedMasked",
+			args: []string{"policeman_why", "c??e"},
+			want: "cop??? -> cope (why:e)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runGuessCommand(tt.args).text(); got != tt.want {
+				t.Errorf("runGuessCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runSearchDictCommand(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propPlaybookCurrent:             "default",
+		propPlaybooksDir:                "./test/data/playbooks",
+		propDictsExt:                    ".test",
+		propSearchDictDefaultDistance:   "1",
+		propSearchDictDefaultMaxResults: "10",
+	})
+
+	dictsDir := getFullDictsDir()
+	fileutils.FilePutContents(dictsDir+"/"+"dict.test", strings.Join(
+		[]string{
+			"cope",
+			"copy",
+			"coypu",
+		}, "\n"))
+	defer fileutils.FileRemove(dictsDir + "/" + "dict.test")
+
+	fileutils.FilePutContents(dictsDir+"/"+"oddDict.test", strings.Join(
+		[]string{
+			"copy",
+		}, "\n"))
+	defer fileutils.FileRemove(dictsDir + "/" + "oddDict.test")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "Exact",
+			args: []string{"copy"},
+			want: "dict.test: 1\noddDict.test: 0",
+		},
+		{
+			name: "ExactNotFound",
+			args: []string{"copz"},
+			want: "404 NOT FOUND",
+		},
+		{
+			name: "FuzzyDefaultDistance",
+			args: []string{"copz", "fuzzy"},
+			want: "dict.test: 0 cope (1)\ndict.test: 1 copy (1)\noddDict.test: 0 copy (1)",
+		},
+		{
+			name: "FuzzyDistance",
+			args: []string{"copu", "fuzzy", "2"},
+			want: "dict.test: 0 cope (1)\ndict.test: 1 copy (1)\ndict.test: 2 coypu (1)\noddDict.test: 0 copy (1)",
+		},
+		{
+			name: "Prefix",
+			args: []string{"coy", "prefix"},
+			want: "dict.test: 2 coypu (2)",
+		},
+		{
+			name: "BadDistance",
+			args: []string{"copz", "fuzzy", "far"},
+			want: "400 BAD REQUEST",
+		},
+		{
+			name: "BadMode",
+			args: []string{"copz", "psychic"},
+			want: "400 BAD REQUEST",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runSearchDictCommand(tt.args).text(); got != tt.want {
+				t.Errorf("runSearchDictCommand() = %v, want %v", got, tt.want)
+			}
+		})
 	}
 }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/ruslanbes/kubrai/property"
+)
+
+// normalize outcomes
+const (
+	normalizeChanged   = "normalized"
+	normalizeUnchanged = "unchanged"
+)
+
+// runNormalizeCommand normalizes the association files of the playbooks, the current one if none is given
+func runNormalizeCommand(args []string) response {
+	playbooks := args
+	if len(playbooks) == 0 {
+		playbooks = []string{property.AsString(propPlaybookCurrent)}
+	}
+
+	res := []string{}
+	for _, name := range playbooks {
+		if !isValidPlaybookName(name) {
+			return statusResponse(statusBadRequest)
+		}
+
+		outcome, err := runNormalize(name)
+		if err != nil {
+			return errorResponse(err)
+		}
+		res = append(res, outcome+": "+name)
+	}
+
+	return newResponse(res)
+}
+
+// runNormalize rewrites the association file of the playbook in the canonical form
+// of its store. The associations stay the same. A text file keeps a backup,
+// a log that doesn't exist yet is written from the text file
+func runNormalize(playbook string) (string, error) {
+	if !playbookExists(playbook) {
+		return "", &playbookError{playbook}
+	}
+
+	dir := getPlaybookDir(playbook)
+	if property.AsString(propAssocStore) == storeLog {
+		file := dir + "/" + assocLogLocation
+		assoc, _, err := readAssocLog(file)
+		if os.IsNotExist(err) {
+			assoc, err = readAssoc(dir + "/" + assocFileLocation)
+		}
+		if err != nil {
+			return "", err
+		}
+		return normalizeFile(file, formatAssocLog(assoc), func() error {
+			return writeAssocLog(file, assoc)
+		})
+	}
+
+	file := dir + "/" + assocFileLocation
+	assoc, err := readAssoc(file)
+	if err != nil {
+		return "", err
+	}
+	return normalizeFile(file, formatAssoc(assoc), func() error {
+		return saveAssoc(file, assoc)
+	})
+}
+
+// normalizeFile writes the file unless it already has the canonical content
+func normalizeFile(file, canonical string, write func() error) (string, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if string(content) == canonical {
+		return normalizeUnchanged, nil
+	}
+
+	return normalizeChanged, write()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/ruslanbes/kubrai/fileutils"
+)
+
+func Test_runNormalizeCommand(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+	})
+
+	assocFile := getFullAssocFileLocation()
+	fileutils.FilePutContents(assocFile, "why:y\npoliceman:cop,bobby\n")
+	canonical := "# kubrai associations v2\npoliceman:cop,bobby\nwhy:y\n"
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "Normalized",
+			args: []string{},
+			want: "normalized: default",
+		},
+		{
+			name: "Unchanged",
+			args: []string{"default"},
+			want: "unchanged: default",
+		},
+		{
+			name: "MissingPlaybook",
+			args: []string{"missing"},
+			want: "404 NOT FOUND\nplaybook not found: missing",
+		},
+		{
+			name: "BadName",
+			args: []string{".."},
+			want: "400 BAD REQUEST",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runNormalizeCommand(tt.args).text(); got != tt.want {
+				t.Errorf("runNormalizeCommand() = %q, want %q", got, tt.want)
+			}
+			if got, _ := ioutil.ReadFile(assocFile); string(got) != canonical {
+				t.Errorf("runNormalizeCommand() left %q, want %q", got, canonical)
+			}
+		})
+	}
+}
+
+func Test_runNormalize_logStore(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propAssocStore:            storeLog,
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+	})
+
+	logFile := getFullAssocStoreLocation()
+	fileutils.FilePutContents(logFile, strings.Join([]string{"+why:y", "+policeman:cop", "-kid", ""}, "\n"))
+	defer fileutils.FileRemove(logFile)
+
+	if got, err := runNormalize("default"); err != nil || got != normalizeChanged {
+		t.Errorf("runNormalize() = %v, %v, want %v", got, err, normalizeChanged)
+	}
+	want := "+policeman:cop\n+why:y\n"
+	if got, _ := ioutil.ReadFile(logFile); string(got) != want {
+		t.Errorf("runNormalize() left %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ruslanbes/kubrai/kubraya"
+	"github.com/ruslanbes/kubrai/solver"
+)
+
+// play session commands
+const (
+	playAccept = "+"    // +[n] accepts candidate n
+	playReject = "-"    // -[n] rejects candidate n
+	playQuit   = "quit" // ends the session
+	playExit   = "exit" // ends the session
+)
+
+const playPrompt = "kubrai> "
+
+// consoleIn, consoleOut and consoleErr are used by interactive verbs.
+// Prompts of verbs that answer a response go to consoleErr, so the response is alone on stdout
+var (
+	consoleIn            = bufio.NewReader(os.Stdin)
+	consoleOut io.Writer = os.Stdout
+	consoleErr io.Writer = os.Stderr
+)
+
+func readConsoleLine() (string, bool) {
+	line, err := consoleIn.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+
+	return strings.TrimRight(line, "\r\n"), true
+}
+
+// playSession remembers the last puzzle and its candidates between lines
+type playSession struct {
+	kubraya    string
+	candidates []solver.Candidate
+	hintLevel  int
+}
+
+func runPlay() string {
+	fmt.Fprintln(consoleOut, "Type a kubraya to solve it, "+playAccept+"[n] to accept, "+playReject+"[n] to reject, any verb to run it, "+playQuit+" to leave")
+
+	s := &playSession{}
+	for {
+		fmt.Fprint(consoleOut, playPrompt)
+		line, ok := readConsoleLine()
+		if !ok {
+			break
+		}
+
+		line = strings.TrimSpace(line)
+		if line == playQuit || line == playExit {
+			break
+		}
+		if line == "" {
+			continue
+		}
+
+		fmt.Fprintln(consoleOut, s.handle(line))
+	}
+
+	return "Bye"
+}
+
+func (s *playSession) handle(line string) string {
+	beginCommand()
+	defer endCommand()
+
+	if strings.HasPrefix(line, playAccept) {
+		return s.accept(line[len(playAccept):])
+	}
+	if strings.HasPrefix(line, playReject) {
+		return s.reject(line[len(playReject):])
+	}
+
+	args := strings.Fields(line)
+	verb := parseVerb(args)
+	args = extractArgs(verb, args)
+	switch verb {
+	case "":
+		return statusBadRequest
+	case vPlay:
+		return statusConflict + "\n" + verb
+	case vSolve, vGuess:
+		if len(args) < minArgs[verb] {
+			return statusBadRequest
+		}
+		cons, rest, ok := parseConstraints(args[1:])
+		if verb == vGuess && ok && len(rest) == 1 {
+			// a guess with its answer adds what it infers, the verb does that
+			return runCommand(verb, args)
+		}
+		if !ok || len(rest) > 0 || !cons.HasParts(kubraya.SplitKubraya(args[0])) {
+			return statusBadRequest
+		}
+		return s.solve(args[0], cons, verb == vGuess)
+	case vHint:
+		if len(args) == 0 {
+			return s.hint()
+		}
+		return runCommand(verb, args)
+	default:
+		return runCommand(verb, args)
+	}
+}
+
+func (s *playSession) solve(kubraya string, cons solver.Constraint, guessOnly bool) string {
+	var cands []solver.Candidate
+	err := solver.ErrNotFound
+	if !guessOnly {
+		cands, err = solveCandidates(kubraya, cons)
+		if notFound(err) {
+			cands, err = fuzzySolveCandidates(kubraya, cons)
+		}
+	}
+	if notFound(err) {
+		cands, err = guessCandidates(kubraya, cons)
+	}
+
+	s.kubraya = kubraya
+	s.candidates = cands
+	s.hintLevel = 0
+	if err != nil {
+		return errorResponse(err).text()
+	}
+
+	return s.listCandidates()
+}
+
+func (s *playSession) listCandidates() string {
+	lines := make([]string, len(s.candidates))
+	for i, c := range s.candidates {
+		lines[i] = strconv.Itoa(i+1) + ". " + formatCandidate(c)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// hint gives the next hint for the current puzzle
+func (s *playSession) hint() string {
+	if s.kubraya == "" {
+		return statusBadRequest
+	}
+
+	if s.hintLevel < hintAnswer {
+		s.hintLevel++
+	}
+	res, err := runHint(s.kubraya, s.hintLevel)
+	if err != nil {
+		return errorResponse(err).text()
+	}
+
+	return res[len(res)-1].line()
+}
+
+func (s *playSession) candidateNum(arg string) (int, bool) {
+	arg = strings.TrimSpace(arg)
+	if arg == "" {
+		return 0, len(s.candidates) > 0
+	}
+
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(s.candidates) {
+		return 0, false
+	}
+
+	return n - 1, true
+}
+
+func (s *playSession) accept(arg string) string {
+	i, ok := s.candidateNum(arg)
+	if !ok {
+		return statusBadRequest
+	}
+
+	learned, err := runAutolearn(s.kubraya, s.candidates[i])
+	if err != nil {
+		return errorResponse(err).text()
+	}
+
+	res := []string{"accepted: " + s.kubraya + " -> " + s.candidates[i].Word}
+	res = append(res, formatAssocChanges(tagLearned, learned)...)
+	s.kubraya = ""
+	s.candidates = nil
+	return strings.Join(res, "\n")
+}
+
+func (s *playSession) reject(arg string) string {
+	i, ok := s.candidateNum(arg)
+	if !ok {
+		return statusBadRequest
+	}
+
+	s.candidates = append(s.candidates[:i], s.candidates[i+1:]...)
+	if len(s.candidates) == 0 {
+		return statusNotFound
+	}
+
+	return s.listCandidates()
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ruslanbes/kubrai/fileutils"
+	"github.com/ruslanbes/kubrai/solver"
+)
+
+func setUpTestConsole(input string) *bytes.Buffer {
+	out := &bytes.Buffer{}
+	consoleIn = bufio.NewReader(strings.NewReader(input))
+	consoleOut = out
+	return out
+}
+
+func Test_runPlay(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAddAutoBothMaxlen:     "0",
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propGuessExplainResults:   "ON",
+		propGuessMaxResults:       "50",
+		propGuessUnknownsLimit:    "2",
+		propGuessUnknownMarker:    "???",
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+		propDictsExt:              ".test",
+		propSolveMaxResults:       "5",
+	})
+
+	dictsDir := getFullDictsDir()
+	fileutils.FilePutContents(dictsDir+"/"+"dict.test", strings.Join(
+		[]string{
+			"boycott",
+			"copy\t100",
+			"cope",
+		}, "\n"))
+	defer fileutils.FileRemove(dictsDir + "/" + "dict.test")
+
+	saveDefaultAssoc(map[string][]string{"policeman": {"cop"}})
+
+	out := setUpTestConsole(strings.Join([]string{
+		"policeman_why",
+		"-1",
+		"+",
+		"add why y",
+		"policeman_why",
+		"+1",
+		"play",
+		"quit",
+		"policeman_why",
+	}, "\n"))
+
+	if got := runPlay(); got != "Bye" {
+		t.Errorf("runPlay() = %v, want %v", got, "Bye")
+	}
+
+	want := []string{
+		"1. cop??? -> copy (why:y)\n2. cop??? -> cope (why:e)",
+		"1. cop??? -> cope (why:e)",
+		"accepted: policeman_why -> cope",
+		"why:y",
+		"1. copy",
+		"accepted: policeman_why -> copy",
+		"409 CONFLICT\nplay",
+	}
+	got := strings.Split(out.String(), playPrompt)
+	got = got[1 : len(got)-1]
+	for i := range got {
+		got[i] = strings.TrimSuffix(got[i], "\n")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("runPlay() printed %q, want %q", got, want)
+	}
+
+	if got, err := runView("why"); err != nil || !reflect.DeepEqual(got, []string{"y"}) {
+		t.Errorf("runPlay() saved why:%v, want why:y", got)
+	}
+}
+
+func Test_playSession_handle(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAddAutoBothMaxlen:     "0",
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propGuessExplainResults:   "ON",
+		propGuessMaxResults:       "50",
+		propGuessUnknownsLimit:    "2",
+		propGuessUnknownMarker:    "???",
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+		propDictsExt:              ".test",
+		propSolveMaxResults:       "5",
+	})
+
+	dictsDir := getFullDictsDir()
+	fileutils.FilePutContents(dictsDir+"/"+"dict.test", strings.Join(
+		[]string{
+			"copy\t100",
+			"cope",
+		}, "\n"))
+	defer fileutils.FileRemove(dictsDir + "/" + "dict.test")
+
+	saveDefaultAssoc(map[string][]string{"policeman": {"cop"}})
+
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{
+			name: "SolveExtraArg",
+			line: "solve policeman_why garbage",
+			want: statusBadRequest,
+		},
+		{
+			name: "GuessBoundOfNoPart",
+			line: "guess policeman_why who:1",
+			want: statusBadRequest,
+		},
+		{
+			name: "GuessWithAnswer",
+			line: "guess policeman_why copy",
+			want: "cop??? -> copy (why:y)\nadded: why:y",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &playSession{}
+			if got := s.handle(tt.line); got != tt.want {
+				t.Errorf("handle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got, err := runView("why"); err != nil || !reflect.DeepEqual(got, []string{"y"}) {
+		t.Errorf("handle() saved why:%v, want why:y", got)
+	}
+}
+
+func Test_playSession_candidateNum(t *testing.T) {
+	s := &playSession{candidates: []solver.Candidate{{Word: "copy"}, {Word: "cope"}}}
+
+	tests := []struct {
+		name  string
+		arg   string
+		want  int
+		want1 bool
+	}{
+		{
+			name:  "Default",
+			arg:   "",
+			want:  0,
+			want1: true,
+		},
+		{
+			name:  "Second",
+			arg:   " 2",
+			want:  1,
+			want1: true,
+		},
+		{
+			name:  "TooBig",
+			arg:   "3",
+			want:  0,
+			want1: false,
+		},
+		{
+			name:  "NotANumber",
+			arg:   "x",
+			want:  0,
+			want1: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := s.candidateNum(tt.arg)
+			if got != tt.want {
+				t.Errorf("candidateNum() got = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("candidateNum() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ruslanbes/kubrai/fileutils"
+	"github.com/ruslanbes/kubrai/property"
+)
+
+// playbook subcommands
+const (
+	playbookUse   = "use"
+	playbookNew   = "new"
+	playbookClone = "clone"
+	playbookRm    = "rm"
+)
+
+// playbookAborted tells that the user did not confirm removing a playbook
+const playbookAborted = "Aborted"
+
+func runPlaybookCommand(args []string) response {
+	if len(args) == 0 {
+		return listPlaybooksResponse()
+	}
+
+	names := args[1:]
+	for _, name := range names {
+		if !isValidPlaybookName(name) {
+			return statusResponse(statusBadRequest)
+		}
+	}
+
+	var status string
+	var err error
+	switch {
+	case args[0] == playbookUse && len(names) == 1:
+		status = runUsePlaybook(names[0])
+	case args[0] == playbookNew && len(names) == 1:
+		status, err = runNewPlaybook(names[0])
+	case args[0] == playbookClone && len(names) == 2:
+		status, err = runClonePlaybook(names[0], names[1])
+	case args[0] == playbookRm && len(names) == 1:
+		status, err = runRemovePlaybook(names[0])
+	default:
+		return statusResponse(statusBadRequest)
+	}
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	switch status {
+	case "":
+		return listPlaybooksResponse()
+	case playbookAborted:
+		return newResponse([]string{status})
+	}
+	return statusResponse(status)
+}
+
+func listPlaybooksResponse() response {
+	res, err := runListPlaybooks()
+	if err != nil {
+		return errorResponse(err)
+	}
+	return newResponse(res)
+}
+
+func isValidPlaybookName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+func playbookExists(name string) bool {
+	stat, err := os.Stat(getPlaybookDir(name))
+	return err == nil && stat.IsDir()
+}
+
+// runUsePlaybook makes the playbook current.
+// Returns error status or empty string
+func runUsePlaybook(name string) string {
+	if !playbookExists(name) {
+		return statusNotFound
+	}
+
+	property.SetProperties(map[string]string{propPlaybookCurrent: name})
+	return ""
+}
+
+// runNewPlaybook creates an empty playbook.
+// Returns error status or empty string, and the error if the playbook can't be written
+func runNewPlaybook(name string) (string, error) {
+	if playbookExists(name) {
+		return statusConflict, nil
+	}
+
+	dir := getPlaybookDir(name)
+	assocFile := dir + "/" + assocFileLocation
+	if err := os.MkdirAll(dir+"/"+dictsDir, 0777); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(assocFile), 0777); err != nil {
+		return "", err
+	}
+
+	return "", ioutil.WriteFile(assocFile, []byte(formatAssoc(map[string][]string{})), 0666)
+}
+
+// runClonePlaybook copies the playbook under a new name.
+// Returns error status or empty string, and the error if the copy fails
+func runClonePlaybook(src, dst string) (string, error) {
+	if !playbookExists(src) {
+		return statusNotFound, nil
+	}
+	if playbookExists(dst) {
+		return statusConflict, nil
+	}
+
+	return "", fileutils.CopyDir(getPlaybookDir(src), getPlaybookDir(dst))
+}
+
+// runRemovePlaybook deletes the playbook once the user confirms it by typing its name.
+// Returns error status or empty string, and the error if the removal fails
+func runRemovePlaybook(name string) (string, error) {
+	if !playbookExists(name) {
+		return statusNotFound, nil
+	}
+	if name == property.AsString(propPlaybookCurrent) {
+		return statusConflict, nil
+	}
+
+	fmt.Fprintf(consoleErr, "Type %s to delete the playbook: ", name)
+	answer, ok := readConsoleLine()
+	if !ok || strings.TrimSpace(answer) != name {
+		return playbookAborted, nil
+	}
+
+	closeStores()
+	return "", os.RemoveAll(getPlaybookDir(name))
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ruslanbes/kubrai/property"
+)
+
+func Test_runPlaybookCommand(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propPlaybookCurrent: "default",
+		propPlaybooksDir:    "./test/data/playbooks",
+	})
+
+	os.MkdirAll(getPlaybookDir("default"), 0777)
+	for _, name := range []string{"pbtest", "pbtestcopy"} {
+		os.RemoveAll(getPlaybookDir(name))
+		defer os.RemoveAll(getPlaybookDir(name))
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		input   string
+		want    string
+		current string
+		exist   map[string]bool
+	}{
+		{
+			name:    "New",
+			args:    []string{"new", "pbtest"},
+			want:    "* default\n  pbtest",
+			current: "default",
+			exist:   map[string]bool{"pbtest/dicts": true, "pbtest/" + assocFileLocation: true},
+		},
+		{
+			name:    "NewAgain",
+			args:    []string{"new", "pbtest"},
+			want:    "409 CONFLICT",
+			current: "default",
+		},
+		{
+			name:    "Clone",
+			args:    []string{"clone", "pbtest", "pbtestcopy"},
+			want:    "* default\n  pbtest\n  pbtestcopy",
+			current: "default",
+			exist:   map[string]bool{"pbtestcopy/" + assocFileLocation: true},
+		},
+		{
+			name:    "CloneMissing",
+			args:    []string{"clone", "nonexistent", "pbtest2"},
+			want:    "404 NOT FOUND",
+			current: "default",
+		},
+		{
+			name:    "Use",
+			args:    []string{"use", "pbtestcopy"},
+			want:    "  default\n  pbtest\n* pbtestcopy",
+			current: "pbtestcopy",
+		},
+		{
+			name:    "UseBadName",
+			args:    []string{"use", "../default"},
+			want:    "400 BAD REQUEST",
+			current: "pbtestcopy",
+		},
+		{
+			name:    "RmCurrent",
+			args:    []string{"rm", "pbtestcopy"},
+			want:    "409 CONFLICT",
+			current: "pbtestcopy",
+		},
+		{
+			name:    "RmNotConfirmed",
+			args:    []string{"rm", "pbtest"},
+			input:   "y\n",
+			want:    "Aborted",
+			current: "pbtestcopy",
+			exist:   map[string]bool{"pbtest": true},
+		},
+		{
+			name:    "Rm",
+			args:    []string{"rm", "pbtest"},
+			input:   "pbtest\n",
+			want:    "  default\n* pbtestcopy",
+			current: "pbtestcopy",
+			exist:   map[string]bool{"pbtest": false},
+		},
+		{
+			name:    "Unknown",
+			args:    []string{"rename", "pbtest"},
+			want:    "400 BAD REQUEST",
+			current: "pbtestcopy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setUpTestConsole(tt.input)
+			if got := runPlaybookCommand(tt.args).text(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runPlaybookCommand() = %v, want %v", got, tt.want)
+			}
+			if got := property.AsString(propPlaybookCurrent); got != tt.current {
+				t.Errorf("runPlaybookCommand() current = %v, want %v", got, tt.current)
+			}
+			for path, want := range tt.exist {
+				_, err := os.Stat(getPlaybookDir(path))
+				if got := err == nil; got != want {
+					t.Errorf("runPlaybookCommand() %v exists = %v, want %v", path, got, want)
+				}
+			}
+		})
+	}
+}
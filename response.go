@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// pseudo-HTTP statuses of responses
+const (
+	statusOK             = "200 OK"
+	statusBadRequest     = "400 BAD REQUEST"
+	statusNotFound       = "404 NOT FOUND"
+	statusConflict       = "409 CONFLICT"
+	statusInternalError  = "500 INTERNAL SERVER ERROR"
+	statusNotImplemented = "501 NOT IMPLEMENTED"
+)
+
+// output formats
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+const formatFlag = "--format"
+
+// response is what a verb answers. As text it is the lines of the verb,
+// or the status and its details when the verb fails.
+// Results are the words of the verb or the objects it reports
+type response struct {
+	Status       string              `json:"status"`
+	Code         int                 `json:"code"`
+	Details      []string            `json:"details,omitempty"` // what failed
+	Results      interface{}         `json:"results"`
+	Explanations []string            `json:"explanations,omitempty"`
+	Associations map[string][]string `json:"associations,omitempty"` // modified associations with their values now, or the learned ones
+	lines        []string
+}
+
+// newResponse makes a successful response of the lines, which are the results unless told otherwise
+func newResponse(lines []string) response {
+	if lines == nil {
+		lines = []string{}
+	}
+
+	return response{Status: statusOK, Code: statusCode(statusOK), Results: lines, lines: lines}
+}
+
+// statusResponse makes a failed response
+func statusResponse(status string, details ...string) response {
+	return response{
+		Status:  status,
+		Code:    statusCode(status),
+		Details: details,
+		Results: []string{},
+		lines:   append([]string{status}, details...),
+	}
+}
+
+func statusCode(status string) int {
+	code, _ := strconv.Atoi(strings.SplitN(status, " ", 2)[0])
+	return code
+}
+
+func (r response) text() string {
+	return strings.Join(r.lines, "\n")
+}
+
+func (r response) json() string {
+	// results are plain data, a response always marshals
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	enc.Encode(r)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (r response) render(format string) string {
+	if format == formatJSON {
+		return r.json()
+	}
+	return r.text()
+}
+
+// extractFormat takes the output format out of the args, text if not given
+func extractFormat(args []string) (string, []string, bool) {
+	format := formatText
+	rest := []string{}
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == formatFlag && i+1 < len(args):
+			i++
+			format = args[i]
+		case strings.HasPrefix(args[i], formatFlag+"="):
+			format = strings.TrimPrefix(args[i], formatFlag+"=")
+		case args[i] == formatFlag:
+			return format, rest, false
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+
+	return format, rest, format == formatText || format == formatJSON
+}
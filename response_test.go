@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func Test_extractFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		want  string
+		want1 []string
+		want2 bool
+	}{
+		{
+			name:  "Default",
+			args:  []string{"solve", "policeman_question"},
+			want:  formatText,
+			want1: []string{"solve", "policeman_question"},
+			want2: true,
+		},
+		{
+			name:  "Separate",
+			args:  []string{"--format", "json", "solve", "policeman_question"},
+			want:  formatJSON,
+			want1: []string{"solve", "policeman_question"},
+			want2: true,
+		},
+		{
+			name:  "Joined",
+			args:  []string{"solve", "policeman_question", "--format=json"},
+			want:  formatJSON,
+			want1: []string{"solve", "policeman_question"},
+			want2: true,
+		},
+		{
+			name:  "Unknown",
+			args:  []string{"--format", "xml", "solve"},
+			want:  "xml",
+			want1: []string{"solve"},
+			want2: false,
+		},
+		{
+			name:  "Missing",
+			args:  []string{"solve", "--format"},
+			want:  formatText,
+			want1: []string{"solve"},
+			want2: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1, got2 := extractFormat(tt.args)
+			if got != tt.want {
+				t.Errorf("extractFormat() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("extractFormat() got1 = %v, want %v", got1, tt.want1)
+			}
+			if got2 != tt.want2 {
+				t.Errorf("extractFormat() got2 = %v, want %v", got2, tt.want2)
+			}
+		})
+	}
+}
+
+func Test_runVerb(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAddAutoBothMaxlen:     "0",
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+	})
+
+	saveDefaultAssoc(map[string][]string{"policeman": {"cop"}})
+
+	tests := []struct {
+		name     string
+		verb     string
+		args     []string
+		wantText string
+		want     response
+	}{
+		{
+			name:     "View",
+			verb:     vView,
+			args:     []string{"policeman"},
+			wantText: "policeman:cop",
+			want:     response{Status: statusOK, Code: 200, Results: []interface{}{"cop"}},
+		},
+		{
+			name:     "Add",
+			verb:     vAdd,
+			args:     []string{"policeman", "bobby"},
+			wantText: "policeman:cop,bobby",
+			want: response{
+				Status:       statusOK,
+				Code:         200,
+				Results:      []interface{}{"policeman:cop,bobby"},
+				Associations: map[string][]string{"policeman": {"cop", "bobby"}},
+			},
+		},
+		{
+			name:     "BadRequest",
+			verb:     vAdd,
+			args:     []string{"policeman"},
+			wantText: "400 BAD REQUEST",
+			want:     response{Status: statusBadRequest, Code: 400, Results: []interface{}{}},
+		},
+		{
+			name:     "PartCountMismatch",
+			verb:     vAddSolution,
+			args:     []string{"policeman_why", "copy"},
+			wantText: "400 BAD REQUEST\npart count mismatch: policeman_why has 2, copy has 1",
+			want: response{
+				Status:  statusBadRequest,
+				Code:    400,
+				Details: []string{"part count mismatch: policeman_why has 2, copy has 1"},
+				Results: []interface{}{},
+			},
+		},
+		{
+			name:     "NotImplemented",
+			verb:     "dance",
+			args:     []string{},
+			wantText: "501 NOT IMPLEMENTED\ndance",
+			want:     response{Status: statusNotImplemented, Code: 501, Details: []string{"dance"}, Results: []interface{}{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := runVerb(tt.verb, tt.args)
+			if got := r.render(formatText); got != tt.wantText {
+				t.Errorf("runVerb() text = %v, want %v", got, tt.wantText)
+			}
+
+			got := response{}
+			if err := json.Unmarshal([]byte(r.render(formatJSON)), &got); err != nil {
+				t.Fatalf("runVerb() json: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runVerb() json = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_response_json(t *testing.T) {
+	tests := []struct {
+		name string
+		r    response
+		want string
+	}{
+		{
+			name: "NoHTMLEscapes",
+			r:    response{Status: statusOK, Code: 200, Results: []string{"copy"}, Explanations: []string{"policeman -> cop"}},
+			want: "{\n  \"status\": \"200 OK\",\n  \"code\": 200,\n  \"results\": [\n    \"copy\"\n  ],\n" +
+				"  \"explanations\": [\n    \"policeman -> cop\"\n  ]\n}",
+		},
+		{
+			name: "Structured",
+			r:    response{Status: statusOK, Code: 200, Results: []hint{{hintLength, "4 letters"}}},
+			want: "{\n  \"status\": \"200 OK\",\n  \"code\": 200,\n  \"results\": [\n" +
+				"    {\n      \"level\": 2,\n      \"hint\": \"4 letters\"\n    }\n  ]\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.json(); got != tt.want {
+				t.Errorf("json() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package solver
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/ruslanbes/kubrai/kubraya"
+)
+
+// Puzzle is a kubraya composed for an answer
+type Puzzle struct {
+	Kubraya   string
+	Clues     []Pair // parts of the kubraya with the chunks they stand for
+	Ambiguity int    // how many other chunks the parts could stand for
+}
+
+// Compose finds the kubrayas of at least two parts whose answer is the word.
+// Puzzles of fewer parts come first, then the ones whose parts have fewer other meanings.
+// Only opts.MaxResults is used
+func (s *Solver) Compose(ctx context.Context, word string, opts Options) ([]Puzzle, error) {
+	reverse := reverseAssoc(s.assoc)
+
+	results := []Puzzle{}
+	splitIntoChunks([]rune(word), reverse, []string{}, func(chunks []string) {
+		if len(chunks) < 2 || ctx.Err() != nil {
+			return
+		}
+
+		clueKeys := make([][]string, len(chunks))
+		for i, chunk := range chunks {
+			// the word is no clue of itself
+			for _, key := range reverse[chunk] {
+				if key != word {
+					clueKeys[i] = append(clueKeys[i], key)
+				}
+			}
+			if len(clueKeys[i]) == 0 {
+				return
+			}
+		}
+
+		for _, keys := range combinations(clueKeys) {
+			p := Puzzle{Kubraya: strings.Join(keys, kubraya.KubrayaSeparator)}
+			for i, key := range keys {
+				p.Clues = append(p.Clues, Pair{key, chunks[i]})
+				p.Ambiguity += len(s.assoc.Get(key)) - 1
+			}
+			results = append(results, p)
+		}
+	})
+	if err := ctx.Err(); err != nil {
+		return []Puzzle{}, err
+	}
+
+	results = rankPuzzles(results, opts.MaxResults)
+	if len(results) == 0 {
+		return results, ErrNotFound
+	}
+	return results, nil
+}
+
+// reverseAssoc maps every association value to the keys it is a value of
+func reverseAssoc(assoc AssocStore) map[string][]string {
+	res := make(map[string][]string)
+	for _, k := range assoc.Keys() {
+		for _, v := range assoc.Get(k) {
+			res[v] = append(res[v], k)
+		}
+	}
+
+	return res
+}
+
+// splitIntoChunks calls found for every split of the runes into chunks that are association values
+func splitIntoChunks(runes []rune, reverse map[string][]string, chunks []string, found func([]string)) {
+	if len(runes) == 0 {
+		found(append([]string{}, chunks...))
+		return
+	}
+
+	for i := 1; i <= len(runes); i++ {
+		chunk := string(runes[:i])
+		if _, ok := reverse[chunk]; ok {
+			splitIntoChunks(runes[i:], reverse, append(chunks, chunk), found)
+		}
+	}
+}
+
+// rankPuzzles sorts the puzzles best first, drops the repeated ones and keeps maxResults of them
+func rankPuzzles(puzzles []Puzzle, maxResults int) []Puzzle {
+	sort.SliceStable(puzzles, func(i, j int) bool {
+		if len(puzzles[i].Clues) != len(puzzles[j].Clues) {
+			return len(puzzles[i].Clues) < len(puzzles[j].Clues)
+		}
+		if puzzles[i].Ambiguity != puzzles[j].Ambiguity {
+			return puzzles[i].Ambiguity < puzzles[j].Ambiguity
+		}
+		return puzzles[i].Kubraya < puzzles[j].Kubraya
+	})
+
+	res := []Puzzle{}
+	seen := make(map[string]bool)
+	for _, p := range puzzles {
+		if seen[p.Kubraya] {
+			continue
+		}
+		seen[p.Kubraya] = true
+		res = append(res, p)
+		if maxResults > 0 && len(res) == maxResults {
+			break
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,128 @@
+package solver
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MaskAny stands for any letter in a mask such as c??y
+const MaskAny = '?'
+
+// MaxBound is the most runes a bound may allow, as regexp repeats are limited
+const MaxBound = 1000
+
+// Bound limits how many runes an unknown part stands for.
+// The zero value means at least one rune and no limit
+type Bound struct {
+	Min int
+	Max int
+}
+
+// widen makes the bound include n runes
+func (b Bound) widen(n int) Bound {
+	if b.Max == 0 {
+		return Bound{n, n}
+	}
+	if n < b.Min {
+		b.Min = n
+	}
+	if n > b.Max {
+		b.Max = n
+	}
+	return b
+}
+
+// pattern gets the regexp group capturing the runes of the unknown part
+func (b Bound) pattern() string {
+	if b.Max == 0 {
+		return "(.+)"
+	}
+
+	return "(.{" + strconv.Itoa(b.Min) + "," + strconv.Itoa(b.Max) + "})"
+}
+
+// Constraint is what is known about the answer besides the kubraya.
+// The zero value allows any word
+type Constraint struct {
+	Length int              // runes in the answer, 0 if unknown
+	Mask   []rune           // letters of the answer, MaskAny where unknown
+	Bounds map[string]Bound // runes the parts stand for when they are unknown
+}
+
+// HasParts tells if the bound overrides are all about the parts
+func (c Constraint) HasParts(parts []string) bool {
+	known := make(map[string]bool, len(parts))
+	for _, part := range parts {
+		known[part] = true
+	}
+
+	for part := range c.Bounds {
+		if !known[part] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validBounds tells if every bound override fits MaxBound with its Min up to its Max
+func (c Constraint) validBounds() bool {
+	for _, b := range c.Bounds {
+		if b.Max < 0 || b.Max > MaxBound || b.Max > 0 && b.Min > b.Max {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Matches tells if the word fits the constraint
+func (c Constraint) Matches(word string) bool {
+	if c.Length == 0 {
+		return true
+	}
+
+	runes := []rune(word)
+	if len(runes) != c.Length {
+		return false
+	}
+	for i, r := range c.Mask {
+		if r != MaskAny && r != runes[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// lenRange narrows the word lengths minLen to maxLen, maxLen 0 meaning no limit, to the constraint.
+// It fails when no length fits both
+func (c Constraint) lenRange(minLen, maxLen int) (int, int, bool) {
+	if c.Length == 0 {
+		return minLen, maxLen, true
+	}
+	if c.Length < minLen || (maxLen > 0 && c.Length > maxLen) {
+		return 0, 0, false
+	}
+
+	return c.Length, c.Length, true
+}
+
+// regexp gets the regexp of the mask, nil if there is no mask
+func (c Constraint) regexp() *regexp.Regexp {
+	if c.Mask == nil {
+		return nil
+	}
+
+	parts := make([]string, len(c.Mask))
+	for i, r := range c.Mask {
+		if r == MaskAny {
+			parts[i] = "."
+		} else {
+			parts[i] = regexp.QuoteMeta(string(r))
+		}
+	}
+
+	return regexp.MustCompile("^" + strings.Join(parts, "") + "$")
+}
@@ -0,0 +1,112 @@
+package solver
+
+import (
+	"testing"
+)
+
+func Test_constraint_matches(t *testing.T) {
+	tests := []struct {
+		name string
+		cons Constraint
+		word string
+		want bool
+	}{
+		{
+			name: "Any",
+			cons: Constraint{},
+			word: "copy",
+			want: true,
+		},
+		{
+			name: "Length",
+			cons: Constraint{Length: 4},
+			word: "папа",
+			want: true,
+		},
+		{
+			name: "WrongLength",
+			cons: Constraint{Length: 4},
+			word: "coypu",
+			want: false,
+		},
+		{
+			name: "Mask",
+			cons: Constraint{Length: 4, Mask: []rune("c??y")},
+			word: "copy",
+			want: true,
+		},
+		{
+			name: "WrongLetter",
+			cons: Constraint{Length: 4, Mask: []rune("c??y")},
+			word: "cope",
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cons.Matches(tt.word); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+			if re := tt.cons.regexp(); re != nil && re.MatchString(tt.word) != tt.want {
+				t.Errorf("regexp() matches %v, want %v", !tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func Test_constraint_lenRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		cons   Constraint
+		minLen int
+		maxLen int
+		want   int
+		want1  int
+		want2  bool
+	}{
+		{
+			name:   "Any",
+			cons:   Constraint{},
+			minLen: 4,
+			maxLen: 0,
+			want:   4,
+			want1:  0,
+			want2:  true,
+		},
+		{
+			name:   "Fits",
+			cons:   Constraint{Length: 6},
+			minLen: 4,
+			maxLen: 0,
+			want:   6,
+			want1:  6,
+			want2:  true,
+		},
+		{
+			name:   "TooShort",
+			cons:   Constraint{Length: 3},
+			minLen: 4,
+			maxLen: 0,
+			want:   0,
+			want1:  0,
+			want2:  false,
+		},
+		{
+			name:   "TooLong",
+			cons:   Constraint{Length: 5},
+			minLen: 4,
+			maxLen: 4,
+			want:   0,
+			want1:  0,
+			want2:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1, got2 := tt.cons.lenRange(tt.minLen, tt.maxLen)
+			if got != tt.want || got1 != tt.want1 || got2 != tt.want2 {
+				t.Errorf("lenRange() = %v, %v, %v, want %v, %v, %v", got, got1, got2, tt.want, tt.want1, tt.want2)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package solver
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ruslanbes/kubrai/kubraya"
+)
+
+// Guess finds the words matching the kubraya when some parts stand for chunks the store doesn't know,
+// best first. Up to opts.UnknownsLimit parts are unknown in a guess, never all of them.
+// The inferred chunks of the unknown parts come with the candidates.
+// Bound overrides must be about the parts and fit MaxBound, otherwise it is ErrBadConstraint
+func (s *Solver) Guess(ctx context.Context, input string, opts Options) ([]Candidate, error) {
+	parts := kubraya.SplitKubraya(input)
+	if !opts.Constraint.HasParts(parts) || !opts.Constraint.validBounds() {
+		return []Candidate{}, ErrBadConstraint
+	}
+
+	marker := opts.marker()
+	kubAssoc, _ := s.kubAssocComplete(parts)
+	kubAssoc = allowUnknowns(kubAssoc, marker)
+	combs := combinations(kubAssoc)
+	combs = filterGuessableCombs(combs, marker, opts.unknownsLimit())
+	combs = sortCombsByBestChances(combs, marker)
+
+	results := []Candidate{}
+	seen := make(map[string]int)
+	maskRe := opts.Constraint.regexp()
+	bounds := s.unknownBounds(parts, opts)
+	for _, comb := range combs {
+		if err := ctx.Err(); err != nil {
+			return []Candidate{}, err
+		}
+
+		minLen, maxLen, ok := opts.Constraint.lenRange(combLenRange(comb, bounds, marker))
+		if !ok {
+			continue
+		}
+
+		wordGuess := strings.Join(comb, "")
+		re, err := regexp.Compile(combToRegexp(comb, bounds, marker))
+		if err != nil {
+			return []Candidate{}, err
+		}
+
+		res := []*regexp.Regexp{re}
+		if maskRe != nil {
+			res = append(res, maskRe)
+		}
+		for _, word := range s.dicts.MatchAll(res, minLen, maxLen, opts.matchLimit(s.dicts)) {
+			chunks := inferChunks(comb, re, word, marker)
+			c := Candidate{
+				Word:     word,
+				Pattern:  wordGuess,
+				Chunks:   chunks,
+				Inferred: inferredPairs(parts, comb, chunks, marker),
+			}
+			c.Score = scoreCandidate(kubAssoc, c, s.dicts.Freq(word))
+			results = addBestCandidate(results, seen, c)
+		}
+	}
+
+	return found(rankCandidates(results, opts.MaxResults))
+}
+
+func nextMultiDimValue(counter, maxCounter []int) ([]int, bool) {
+	ok := false
+	for i, c := range counter {
+		if c != maxCounter[i] {
+			counter[i] = c + 1
+			ok = true
+			break
+		} else {
+			counter[i] = 0
+		}
+	}
+
+	return counter, ok
+}
+
+// combinations gets every way to take one item of each list, the first list changing fastest
+func combinations(items [][]string) [][]string {
+	lenitems := len(items)
+
+	maxCounter := make([]int, lenitems)
+	for i, item := range items {
+		maxCounter[i] = len(item) - 1
+	}
+
+	ok := true
+	counter := make([]int, lenitems)
+	combs := make([][]string, 0, lenitems*10)
+	for true {
+		comb := make([]string, lenitems)
+		for i, j := range counter {
+			comb[i] = items[i][j]
+		}
+		combs = append(combs, comb)
+
+		counter, ok = nextMultiDimValue(counter, maxCounter)
+		if !ok {
+			break
+		}
+	}
+
+	return combs
+}
+
+func allowUnknowns(kubAssoc [][]string, marker string) [][]string {
+	for i, list := range kubAssoc {
+		if len(list) == 0 {
+			kubAssoc[i] = []string{marker}
+		} else {
+			kubAssoc[i] = append(kubAssoc[i], marker)
+		}
+	}
+
+	return kubAssoc
+}
+
+// combToRegexp builds a regexp capturing the chunk of every unknown in the comb.
+// The unknown at i stands for bounds[i] runes, any number of them if there is no such bound
+func combToRegexp(comb []string, bounds []Bound, marker string) string {
+	parts := make([]string, len(comb))
+	for i, chunk := range comb {
+		if chunk == marker {
+			parts[i] = boundAt(bounds, i).pattern()
+		} else {
+			parts[i] = regexp.QuoteMeta(chunk)
+		}
+	}
+
+	return "^" + strings.Join(parts, phraseSeparatorRegexp) + "$"
+}
+
+// combLenRange tells how long in runes the words matching the comb can be, 0 max means no limit.
+// Phrases are up to one separator longer per join
+func combLenRange(comb []string, bounds []Bound, marker string) (int, int) {
+	minLen := 0
+	maxLen := 0
+	unlimited := false
+	for i, chunk := range comb {
+		if chunk != marker {
+			minLen += len([]rune(chunk))
+			maxLen += len([]rune(chunk))
+			continue
+		}
+
+		b := boundAt(bounds, i)
+		if b.Max == 0 {
+			minLen++
+			unlimited = true
+			continue
+		}
+		minLen += b.Min
+		maxLen += b.Max
+	}
+
+	if unlimited {
+		return minLen, 0
+	}
+	return minLen, maxLen + len(comb) - 1
+}
+
+func boundAt(bounds []Bound, i int) Bound {
+	if i < len(bounds) {
+		return bounds[i]
+	}
+
+	return Bound{}
+}
+
+// unknownBounds tells how many runes every part may stand for when it is unknown.
+// Overrides come first. Otherwise, if opts.BoundUnknowns is on, a part stands for as many runes
+// as the values of the store
+func (s *Solver) unknownBounds(parts []string, opts Options) []Bound {
+	all := Bound{}
+	if opts.BoundUnknowns {
+		all = valueLenBound(s.assoc)
+	}
+
+	bounds := make([]Bound, len(parts))
+	for i, part := range parts {
+		if b, ok := opts.Constraint.Bounds[part]; ok {
+			bounds[i] = b
+		} else {
+			bounds[i] = all
+		}
+	}
+
+	return bounds
+}
+
+// valueLenBound gets the range of value lengths of all keys
+func valueLenBound(assoc AssocStore) Bound {
+	all := Bound{}
+	for _, k := range assoc.Keys() {
+		for _, v := range assoc.Get(k) {
+			all = all.widen(len([]rune(v)))
+		}
+	}
+
+	return all
+}
+
+// inferChunks fills the unknowns of the comb with the chunks they matched in the word
+func inferChunks(comb []string, re *regexp.Regexp, word, marker string) []string {
+	match := re.FindStringSubmatch(word)
+	chunks := make([]string, len(comb))
+	group := 1
+	for i, chunk := range comb {
+		if chunk == marker && group < len(match) {
+			chunk = strings.Trim(match[group], phraseSeparators)
+			group++
+		}
+		chunks[i] = chunk
+	}
+
+	return chunks
+}
+
+// inferredPairs pairs the unknown parts of the comb with their inferred chunks
+func inferredPairs(parts, comb, chunks []string, marker string) []Pair {
+	res := []Pair{}
+	for i, chunk := range comb {
+		if chunk == marker && i < len(parts) && i < len(chunks) {
+			res = append(res, Pair{parts[i], chunks[i]})
+		}
+	}
+
+	return res
+}
+
+func countValsInSlice(slc []string, val string) int {
+	counter := 0
+	for _, v := range slc {
+		if v == val {
+			counter++
+		}
+	}
+
+	return counter
+}
+
+func isCombGuessable(comb []string, marker string, unknownsLimit int) bool {
+	unk := countValsInSlice(comb, marker)
+
+	// maybe need smarter criteria - 3 of out 5 unknown is fine
+	// rely on percentage??
+	return unk <= unknownsLimit && unk < len(comb)
+}
+
+func filterGuessableCombs(combs [][]string, marker string, unknownsLimit int) [][]string {
+	res := [][]string{}
+	for _, comb := range combs {
+		if isCombGuessable(comb, marker, unknownsLimit) {
+			res = append(res, comb)
+		}
+	}
+
+	return res
+}
+
+func sortCombsByBestChances(combs [][]string, marker string) [][]string {
+	sort.Slice(combs, func(i, j int) bool {
+		return countValsInSlice(combs[i], marker) < countValsInSlice(combs[j], marker)
+	})
+
+	return combs
+}
@@ -0,0 +1,340 @@
+package solver
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/ruslanbes/kubrai/dict"
+)
+
+func Test_nextMultiDimValue(t *testing.T) {
+	type args struct {
+		counter    []int
+		maxCounter []int
+	}
+	tests := []struct {
+		name  string
+		args  args
+		want  []int
+		want1 bool
+	}{
+		{
+			name:  "Test000",
+			args:  args{[]int{0, 0, 0}, []int{1, 2, 3}},
+			want:  []int{1, 0, 0},
+			want1: true,
+		},
+		{
+			name:  "Test100",
+			args:  args{[]int{1, 0, 0}, []int{1, 2, 3}},
+			want:  []int{0, 1, 0},
+			want1: true,
+		},
+		{
+			name:  "Test010",
+			args:  args{[]int{0, 1, 0}, []int{1, 2, 3}},
+			want:  []int{1, 1, 0},
+			want1: true,
+		},
+		{
+			name:  "Test110",
+			args:  args{[]int{1, 1, 0}, []int{1, 2, 3}},
+			want:  []int{0, 2, 0},
+			want1: true,
+		},
+		{
+			name:  "Test020",
+			args:  args{[]int{0, 2, 0}, []int{1, 2, 3}},
+			want:  []int{1, 2, 0},
+			want1: true,
+		},
+		{
+			name:  "Test120",
+			args:  args{[]int{1, 2, 0}, []int{1, 2, 3}},
+			want:  []int{0, 0, 1},
+			want1: true,
+		},
+		{
+			name:  "Test003",
+			args:  args{[]int{0, 0, 3}, []int{1, 2, 3}},
+			want:  []int{1, 0, 3},
+			want1: true,
+		},
+		{
+			name:  "Test123",
+			args:  args{[]int{1, 2, 3}, []int{1, 2, 3}},
+			want:  []int{0, 0, 0},
+			want1: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := nextMultiDimValue(tt.args.counter, tt.args.maxCounter)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("nextMultiDimValue() got = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("nextMultiDimValue() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func Test_combinations(t *testing.T) {
+	type args struct {
+		items [][]string
+	}
+	tests := []struct {
+		name string
+		args args
+		want [][]string
+	}{
+		{
+			name: "Test1",
+			args: args{[][]string{{"a", "b", "c"}, {"1", "2"}, {"TRUE"}, {"?"}}},
+			want: [][]string{
+				{"a", "1", "TRUE", "?"},
+				{"b", "1", "TRUE", "?"},
+				{"c", "1", "TRUE", "?"},
+				{"a", "2", "TRUE", "?"},
+				{"b", "2", "TRUE", "?"},
+				{"c", "2", "TRUE", "?"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := combinations(tt.args.items); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("combinations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_allowUnknowns(t *testing.T) {
+	type args struct {
+		kubAssoc [][]string
+	}
+	tests := []struct {
+		name string
+		args args
+		want [][]string
+	}{
+		{
+			name: "Test1",
+			args: args{[][]string{{"one", "two"}, {}, {"a", "b", "c"}}},
+			want: [][]string{{"one", "two", "???"}, {"???"}, {"a", "b", "c", "???"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allowUnknowns(tt.args.kubAssoc, DefaultUnknownMarker); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allowUnknowns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_combToRegexp(t *testing.T) {
+	tests := []struct {
+		name   string
+		comb   []string
+		bounds []Bound
+		want   string
+	}{
+		{
+			name: "Known",
+			comb: []string{"cop", "y"},
+			want: `^cop[ \-]?y$`,
+		},
+		{
+			name:   "Bounded",
+			comb:   []string{"???", "x", "???"},
+			bounds: []Bound{{1, 2}, {}, {}},
+			want:   `^(.{1,2})[ \-]?x[ \-]?(.+)$`,
+		},
+		{
+			name: "Unknowns",
+			comb: []string{"???", "x", "???"},
+			want: `^(.+)[ \-]?x[ \-]?(.+)$`,
+		},
+		{
+			name: "Quoted",
+			comb: []string{"a.b", "???"},
+			want: `^a\.b[ \-]?(.+)$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := combToRegexp(tt.comb, tt.bounds, DefaultUnknownMarker); got != tt.want {
+				t.Errorf("combToRegexp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_inferChunks(t *testing.T) {
+	tests := []struct {
+		name string
+		comb []string
+		word string
+		want []string
+	}{
+		{
+			name: "Last",
+			comb: []string{"cop", "???"},
+			word: "copy",
+			want: []string{"cop", "y"},
+		},
+		{
+			name: "Middle",
+			comb: []string{"pro", "???", "mi", "ty"},
+			word: "proximity",
+			want: []string{"pro", "xi", "mi", "ty"},
+		},
+		{
+			name: "Cyrillic",
+			comb: []string{"???", "па"},
+			word: "папа",
+			want: []string{"па", "па"},
+		},
+		{
+			name: "Phrase",
+			comb: []string{"???", "cream"},
+			word: "ice cream",
+			want: []string{"ice", "cream"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re := regexp.MustCompile(combToRegexp(tt.comb, nil, DefaultUnknownMarker))
+			if got := inferChunks(tt.comb, re, tt.word, DefaultUnknownMarker); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("inferChunks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_inferredPairs(t *testing.T) {
+	type args struct {
+		parts  []string
+		comb   []string
+		chunks []string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []Pair
+	}{
+		{
+			name: "None",
+			args: args{[]string{"policeman", "why"}, []string{"cop", "y"}, []string{"cop", "y"}},
+			want: []Pair{},
+		},
+		{
+			name: "Two",
+			args: args{[]string{"amateur", "psi", "6", "thanks"}, []string{"pro", "???", "mi", "???"}, []string{"pro", "xi", "mi", "ty"}},
+			want: []Pair{{"psi", "xi"}, {"thanks", "ty"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inferredPairs(tt.args.parts, tt.args.comb, tt.args.chunks, DefaultUnknownMarker); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("inferredPairs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_combLenRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		comb   []string
+		bounds []Bound
+		want   int
+		want1  int
+	}{
+		{
+			name:  "Known",
+			comb:  []string{"cop", "y"},
+			want:  4,
+			want1: 5,
+		},
+		{
+			name:   "Bounded",
+			comb:   []string{"???", "па", "???"},
+			bounds: []Bound{{1, 2}, {}, {2, 3}},
+			want:   5,
+			want1:  9,
+		},
+		{
+			name:   "PartlyBounded",
+			comb:   []string{"???", "па", "???"},
+			bounds: []Bound{{1, 2}, {}, {}},
+			want:   4,
+			want1:  0,
+		},
+		{
+			name:  "Unknowns",
+			comb:  []string{"???", "па", "???"},
+			want:  4,
+			want1: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := combLenRange(tt.comb, tt.bounds, DefaultUnknownMarker)
+			if got != tt.want {
+				t.Errorf("combLenRange() got = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("combLenRange() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func Test_unknownBounds(t *testing.T) {
+	s := New(MapStore{
+		"policeman": {"cop", "bobby"},
+		"why":       {"y"},
+		"tea":       {"t", "chai"},
+	}, dict.NewSet())
+
+	tests := []struct {
+		name   string
+		bound  bool
+		parts  []string
+		bounds map[string]Bound
+		want   []Bound
+	}{
+		{
+			name:  "Off",
+			bound: false,
+			parts: []string{"who", "question"},
+			want:  []Bound{{}, {}},
+		},
+		{
+			name:  "AllValues",
+			bound: true,
+			parts: []string{"who", "question"},
+			want:  []Bound{{1, 5}, {1, 5}},
+		},
+		{
+			name:   "Override",
+			bound:  true,
+			parts:  []string{"who", "question"},
+			bounds: map[string]Bound{"question": {1, 2}},
+			want:   []Bound{{1, 5}, {1, 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{BoundUnknowns: tt.bound, Constraint: Constraint{Bounds: tt.bounds}}
+			if got := s.unknownBounds(tt.parts, opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unknownBounds() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package solver
+
+import (
+	"math"
+	"sort"
+)
+
+// scores of candidates. A candidate starts with scoreBase and loses points
+// for every weakness and gains points for the word frequency
+const (
+	scoreBase            = 100
+	scorePerAssocPos     = 5  // per position of a chunk in its association list
+	scorePerUnknown      = 20 // per unknown part
+	scorePerWildcardRune = 3  // per rune matched by unknown parts
+	scorePerFreqDecade   = 2  // per power of ten of the word frequency
+	scorePerEdit         = 15 // per letter dropped or inserted by fuzzy solve
+	scorePerMerge        = 10 // per doubled letter merged by fuzzy solve
+)
+
+// scoreCandidate rates the candidate found with the kubraya associations
+func scoreCandidate(kubAssoc [][]string, c Candidate, freq int) int {
+	score := scoreBase
+	for i, chunk := range c.Chunks {
+		if i >= len(kubAssoc) {
+			continue
+		}
+		for pos, val := range kubAssoc[i] {
+			if val == chunk {
+				score -= pos * scorePerAssocPos
+				break
+			}
+		}
+	}
+
+	score -= len(c.Inferred) * scorePerUnknown
+	for _, p := range c.Inferred {
+		score -= len([]rune(p.Val)) * scorePerWildcardRune
+	}
+
+	for _, e := range c.Edits {
+		if e.Kind == EditMerge {
+			score -= scorePerMerge
+		} else {
+			score -= scorePerEdit
+		}
+	}
+
+	if freq > 0 {
+		score += int(math.Log10(float64(freq))) * scorePerFreqDecade
+	}
+
+	return score
+}
+
+// rankCandidates sorts the candidates best first and keeps maxResults of them
+func rankCandidates(cands []Candidate, maxResults int) []Candidate {
+	sort.SliceStable(cands, func(i, j int) bool {
+		if cands[i].Score != cands[j].Score {
+			return cands[i].Score > cands[j].Score
+		}
+		return cands[i].Word < cands[j].Word
+	})
+
+	if maxResults > 0 && len(cands) > maxResults {
+		cands = cands[:maxResults]
+	}
+	return cands
+}
+
+// addBestCandidate adds the candidate unless the same word is already there with a better score
+func addBestCandidate(cands []Candidate, found map[string]int, c Candidate) []Candidate {
+	i, ok := found[c.Word]
+	if !ok {
+		found[c.Word] = len(cands)
+		return append(cands, c)
+	}
+
+	if c.Score > cands[i].Score {
+		cands[i] = c
+	}
+	return cands
+}
@@ -0,0 +1,63 @@
+package solver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_scoreCandidate(t *testing.T) {
+	kubAssoc := [][]string{{"cop", "thief"}, {"y", "not", "???"}}
+
+	type args struct {
+		c    Candidate
+		freq int
+	}
+	tests := []struct {
+		name string
+		args args
+		want int
+	}{
+		{
+			name: "Best",
+			args: args{Candidate{Word: "copy", Chunks: []string{"cop", "y"}}, 0},
+			want: 100,
+		},
+		{
+			name: "Positions",
+			args: args{Candidate{Word: "thiefnot", Chunks: []string{"thief", "not"}}, 0},
+			want: 90,
+		},
+		{
+			name: "Unknown",
+			args: args{Candidate{Word: "cope", Chunks: []string{"cop", "e"}, Inferred: []Pair{{"why", "e"}}}, 0},
+			want: 77,
+		},
+		{
+			name: "Freq",
+			args: args{Candidate{Word: "copy", Chunks: []string{"cop", "y"}}, 12345},
+			want: 108,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scoreCandidate(kubAssoc, tt.args.c, tt.args.freq); got != tt.want {
+				t.Errorf("scoreCandidate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_rankCandidates(t *testing.T) {
+	cands := []Candidate{
+		{Word: "b", Score: 50},
+		{Word: "c", Score: 90},
+		{Word: "a", Score: 50},
+		{Word: "d", Score: 10},
+	}
+
+	got := Words(rankCandidates(cands, 3))
+	want := []string{"c", "a", "b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rankCandidates() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,269 @@
+// Package solver answers kubrayas with an association store and a set of dictionaries
+package solver
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/ruslanbes/kubrai/dict"
+	"github.com/ruslanbes/kubrai/kubraya"
+)
+
+// DefaultUnknownMarker stands for an unknown part in guess patterns unless the options tell another one
+const DefaultUnknownMarker = "???"
+
+// DefaultUnknownsLimit is how many parts a guess may have unknown unless the options tell another number
+const DefaultUnknownsLimit = 1
+
+var (
+	// ErrNotFound is returned when nothing answers the kubraya
+	ErrNotFound = errors.New("solver: not found")
+	// ErrBadConstraint is returned when the constraint bounds a part the kubraya doesn't have
+	ErrBadConstraint = errors.New("solver: bad constraint")
+)
+
+// UnknownPartError is returned by Solve when a part of the kubraya has no associations
+type UnknownPartError struct {
+	Part string
+}
+
+func (e *UnknownPartError) Error() string {
+	return "solver: no associations for " + e.Part
+}
+
+// AssocStore is where the solver takes the associations from
+type AssocStore interface {
+	// Get gets the values of the key, best first
+	Get(key string) []string
+	// Keys lists the keys in order
+	Keys() []string
+}
+
+// MapStore is an association store over a map of keys to their values
+type MapStore map[string][]string
+
+// Get gets the values of the key
+func (m MapStore) Get(key string) []string {
+	return m[key]
+}
+
+// Keys lists the keys sorted
+func (m MapStore) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Options tune a call of the solver
+type Options struct {
+	MaxResults    int        // results kept, best first, all of them when 0
+	UnknownMarker string     // stands for an unknown part in guess patterns, DefaultUnknownMarker if empty
+	UnknownsLimit int        // unknown parts a guess may have, DefaultUnknownsLimit when 0
+	FuzzyEdits    int        // edits Solve may apply where parts join when nothing solves exactly
+	BoundUnknowns bool       // limit unknown parts to the value lengths of the store
+	Constraint    Constraint // what is known about the answer
+}
+
+func (o Options) marker() string {
+	if o.UnknownMarker == "" {
+		return DefaultUnknownMarker
+	}
+
+	return o.UnknownMarker
+}
+
+func (o Options) unknownsLimit() int {
+	if o.UnknownsLimit == 0 {
+		return DefaultUnknownsLimit
+	}
+
+	return o.UnknownsLimit
+}
+
+// matchLimit is how many words a guess pattern may match, any number when MaxResults is 0
+func (o Options) matchLimit(dicts *dict.Set) int {
+	if o.MaxResults == 0 {
+		return dicts.Len()
+	}
+
+	return o.MaxResults
+}
+
+// Pair is a single association
+type Pair struct {
+	Key string
+	Val string
+}
+
+// Candidate is a possible answer of a kubraya
+type Candidate struct {
+	Word     string
+	Pattern  string   // guess pattern the word matched, empty for solves
+	Chunks   []string // chunk of the word each kubraya part stands for
+	Inferred []Pair   // unknown parts with the chunks they matched
+	Edits    []Edit   // edits of a fuzzy solve
+	Score    int      // the higher the better
+}
+
+// Guessed tells if the candidate comes from a guess
+func (c Candidate) Guessed() bool {
+	return c.Pattern != ""
+}
+
+// Fuzzy tells if the candidate needed edits where parts join
+func (c Candidate) Fuzzy() bool {
+	return len(c.Edits) > 0
+}
+
+// Words gets the words of the candidates
+func Words(cands []Candidate) []string {
+	words := make([]string, len(cands))
+	for i, c := range cands {
+		words[i] = c.Word
+	}
+
+	return words
+}
+
+// Solver answers kubrayas. It only reads the store and the dictionaries
+type Solver struct {
+	assoc AssocStore
+	dicts *dict.Set
+}
+
+// New creates a solver over the store and the dictionaries
+func New(assoc AssocStore, dicts *dict.Set) *Solver {
+	return &Solver{assoc: assoc, dicts: dicts}
+}
+
+// kubAssoc gets the values of every part, failing on the first part without any
+func (s *Solver) kubAssoc(parts []string) ([][]string, error) {
+	kubAssoc := make([][]string, len(parts))
+	for i, part := range parts {
+		kubAssoc[i] = s.assoc.Get(part)
+		if len(kubAssoc[i]) == 0 {
+			return [][]string{}, &UnknownPartError{part}
+		}
+	}
+
+	return kubAssoc, nil
+}
+
+// kubAssocComplete gets the values of every part and tells if every part has some
+func (s *Solver) kubAssocComplete(parts []string) ([][]string, bool) {
+	complete := true
+	kubAssoc := make([][]string, len(parts))
+	for i, part := range parts {
+		kubAssoc[i] = append([]string{}, s.assoc.Get(part)...)
+		if len(kubAssoc[i]) == 0 {
+			complete = false
+		}
+	}
+
+	return kubAssoc, complete
+}
+
+func found(results []Candidate) ([]Candidate, error) {
+	if len(results) == 0 {
+		return results, ErrNotFound
+	}
+
+	return results, nil
+}
+
+// Solve finds the words made of one value of every part of the kubraya, best first.
+// If there are none and opts.FuzzyEdits is set, it solves fuzzily
+func (s *Solver) Solve(ctx context.Context, input string, opts Options) ([]Candidate, error) {
+	parts := kubraya.SplitKubraya(input)
+	if !opts.Constraint.HasParts(parts) {
+		return []Candidate{}, ErrBadConstraint
+	}
+
+	kubAssoc, err := s.kubAssoc(parts)
+	if err != nil {
+		return []Candidate{}, err
+	}
+
+	results, err := s.solve(ctx, kubAssoc, opts)
+	if err == ErrNotFound && opts.FuzzyEdits > 0 {
+		return s.solveFuzzy(ctx, kubAssoc, opts)
+	}
+	return results, err
+}
+
+// SolveFuzzy finds the words made of one value of every part of the kubraya with up to
+// opts.FuzzyEdits edits where parts join. Only words needing edits are returned
+func (s *Solver) SolveFuzzy(ctx context.Context, input string, opts Options) ([]Candidate, error) {
+	parts := kubraya.SplitKubraya(input)
+	if !opts.Constraint.HasParts(parts) {
+		return []Candidate{}, ErrBadConstraint
+	}
+
+	kubAssoc, err := s.kubAssoc(parts)
+	if err != nil {
+		return []Candidate{}, err
+	}
+
+	return s.solveFuzzy(ctx, kubAssoc, opts)
+}
+
+func (s *Solver) solve(ctx context.Context, kubAssoc [][]string, opts Options) ([]Candidate, error) {
+	results := []Candidate{}
+	seen := make(map[string]int)
+
+	walkSolutions(ctx, s.dicts.Trie().Root(), kubAssoc, []string{}, "", func(comb []string, word string) {
+		if !opts.Constraint.Matches(word) {
+			return
+		}
+		c := Candidate{Word: word, Chunks: comb}
+		c.Score = scoreCandidate(kubAssoc, c, s.dicts.Freq(word))
+		results = addBestCandidate(results, seen, c)
+	})
+	if err := ctx.Err(); err != nil {
+		return []Candidate{}, err
+	}
+
+	return found(rankCandidates(results, opts.MaxResults))
+}
+
+func (s *Solver) solveFuzzy(ctx context.Context, kubAssoc [][]string, opts Options) ([]Candidate, error) {
+	results := []Candidate{}
+	if opts.FuzzyEdits <= 0 {
+		return results, ErrNotFound
+	}
+
+	seen := make(map[string]int)
+	w := &fuzzyWalk{ctx: ctx, kubAssoc: kubAssoc, budget: opts.FuzzyEdits}
+	w.found = func(chunks []string, word string, edits []Edit) {
+		if !opts.Constraint.Matches(word) {
+			return
+		}
+		c := Candidate{Word: word, Chunks: chunks, Edits: edits}
+		c.Score = scoreCandidate(kubAssoc, c, s.dicts.Freq(word))
+		results = addBestCandidate(results, seen, c)
+	}
+	w.walk(s.dicts.Trie().Root(), []string{}, []rune{}, []Edit{})
+	if err := ctx.Err(); err != nil {
+		return []Candidate{}, err
+	}
+
+	return found(rankCandidates(results, opts.MaxResults))
+}
+
+// SearchDict finds the dictionary entries for the word in one of the dict search modes, closest first
+func (s *Solver) SearchDict(ctx context.Context, word, mode string, maxDistance, maxResults int) ([]dict.Found, error) {
+	if err := ctx.Err(); err != nil {
+		return []dict.Found{}, err
+	}
+
+	res, err := s.dicts.Search(word, mode, maxDistance, maxResults)
+	if err == nil && len(res) == 0 {
+		err = ErrNotFound
+	}
+	return res, err
+}
@@ -0,0 +1,229 @@
+package solver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ruslanbes/kubrai/dict"
+)
+
+func setUpTestSolver() *Solver {
+	set := dict.NewSet()
+	set.AddDict("dict.test", []string{"boycott", "cope", "copy", "coypu", "ice cream"})
+
+	return New(MapStore{
+		"policeman": {"cop"},
+		"why":       {"y"},
+		"kid":       {"bo"},
+		"bed":       {"cot"},
+		"tea":       {"t"},
+		"cold":      {"ice"},
+		"milk":      {"cream"},
+		"empty":     {""},
+	}, set)
+}
+
+func TestSolver_Solve(t *testing.T) {
+	s := setUpTestSolver()
+
+	tests := []struct {
+		name    string
+		input   string
+		opts    Options
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "Solved",
+			input: "policeman_why",
+			want:  []string{"copy"},
+		},
+		{
+			name:  "Phrase",
+			input: "cold_milk",
+			want:  []string{"ice cream"},
+		},
+		{
+			name:    "NotFound",
+			input:   "policeman_tea",
+			want:    []string{},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:  "Fuzzy",
+			input: "kid_bed_tea",
+			opts:  Options{FuzzyEdits: 1},
+			want:  []string{"boycott"},
+		},
+		{
+			name:    "FuzzyOff",
+			input:   "kid_bed_tea",
+			want:    []string{},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "EmptyValueFuzzy",
+			input:   "policeman_empty",
+			opts:    Options{FuzzyEdits: 1},
+			want:    []string{},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "UnknownPart",
+			input:   "policeman_who",
+			want:    []string{},
+			wantErr: &UnknownPartError{"who"},
+		},
+		{
+			name:    "BadConstraint",
+			input:   "policeman_why",
+			opts:    Options{Constraint: Constraint{Bounds: map[string]Bound{"who": {Min: 1, Max: 2}}}},
+			want:    []string{},
+			wantErr: ErrBadConstraint,
+		},
+		{
+			name:    "Constraint",
+			input:   "policeman_why",
+			opts:    Options{Constraint: Constraint{Length: 5}},
+			want:    []string{},
+			wantErr: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Solve(context.Background(), tt.input, tt.opts)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("Solve() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(Words(got), tt.want) {
+				t.Errorf("Solve() = %v, want %v", Words(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestSolver_Solve_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := setUpTestSolver().Solve(ctx, "policeman_why", Options{}); err != context.Canceled {
+		t.Errorf("Solve() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestSolver_Guess(t *testing.T) {
+	s := setUpTestSolver()
+
+	tests := []struct {
+		name    string
+		input   string
+		opts    Options
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "Unknown",
+			input: "policeman_question",
+			opts:  Options{MaxResults: 10, UnknownsLimit: 1},
+			want:  []string{"cope", "copy"},
+		},
+		{
+			name:  "Masked",
+			input: "policeman_question",
+			opts:  Options{MaxResults: 10, UnknownsLimit: 1, Constraint: Constraint{Length: 4, Mask: []rune("???y")}},
+			want:  []string{"copy"},
+		},
+		{
+			name:    "BoundTooWide",
+			input:   "policeman_question",
+			opts:    Options{MaxResults: 10, UnknownsLimit: 1, Constraint: Constraint{Bounds: map[string]Bound{"question": {Min: 1, Max: MaxBound + 1}}}},
+			want:    []string{},
+			wantErr: ErrBadConstraint,
+		},
+		{
+			name:  "ZeroValue",
+			input: "policeman_question",
+			opts:  Options{},
+			want:  []string{"cope", "copy"},
+		},
+		{
+			name:    "OverLimit",
+			input:   "policeman_what_question",
+			opts:    Options{MaxResults: 10},
+			want:    []string{},
+			wantErr: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Guess(context.Background(), tt.input, tt.opts)
+			if err != tt.wantErr {
+				t.Errorf("Guess() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(Words(got), tt.want) {
+				t.Errorf("Guess() = %v, want %v", Words(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestSolver_Compose(t *testing.T) {
+	got, err := setUpTestSolver().Compose(context.Background(), "copy", Options{})
+	want := []Puzzle{{Kubraya: "policeman_why", Clues: []Pair{{"policeman", "cop"}, {"why", "y"}}}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Compose() = %v, %v, want %v", got, err, want)
+	}
+
+	if _, err := setUpTestSolver().Compose(context.Background(), "coypu", Options{}); err != ErrNotFound {
+		t.Errorf("Compose() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestSolver_SearchDict(t *testing.T) {
+	s := setUpTestSolver()
+
+	tests := []struct {
+		name    string
+		word    string
+		mode    string
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "Prefix",
+			word: "cop",
+			mode: dict.ModePrefix,
+			want: []string{"cope", "copy"},
+		},
+		{
+			name:    "NotFound",
+			word:    "xyz",
+			mode:    dict.ModeExact,
+			want:    []string{},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "BadMode",
+			word:    "copy",
+			mode:    "sideways",
+			want:    []string{},
+			wantErr: dict.ErrUnknownMode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.SearchDict(context.Background(), tt.word, tt.mode, 0, 10)
+			if err != tt.wantErr {
+				t.Errorf("SearchDict() error = %v, want %v", err, tt.wantErr)
+			}
+			got := make([]string, len(res))
+			for i, f := range res {
+				got[i] = f.Word
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchDict() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package solver
+
+import (
+	"context"
+
+	"github.com/ruslanbes/kubrai/dict"
+)
+
+// phraseSeparators may join the chunks of a multi-word answer such as ice cream or x-ray
+const phraseSeparators = " -"
+
+// phraseSeparatorRegexp matches an optional phrase separator
+const phraseSeparatorRegexp = `[ \-]?`
+
+// walkSolutions calls found for every combination of chunks forming a dictionary word or phrase,
+// with the word as the dictionary writes it.
+// A combination is dropped as soon as no word starts with its first chunks. Stops once ctx is done
+func walkSolutions(ctx context.Context, n *dict.Node, kubAssoc [][]string, chunks []string, word string, found func([]string, string)) {
+	if ctx.Err() != nil {
+		return
+	}
+	if len(chunks) == len(kubAssoc) {
+		if n.IsWord() {
+			found(append([]string{}, chunks...), word)
+		}
+		return
+	}
+
+	for _, chunk := range kubAssoc[len(chunks)] {
+		if next := n.Walk(chunk); next != nil {
+			walkSolutions(ctx, next, kubAssoc, append(chunks, chunk), word+chunk, found)
+		}
+		if len(chunks) == 0 {
+			continue
+		}
+
+		for _, sep := range phraseSeparators {
+			if next := n.Walk(string(sep) + chunk); next != nil {
+				walkSolutions(ctx, next, kubAssoc, append(chunks, chunk), word+string(sep)+chunk, found)
+			}
+		}
+	}
+}
+
+// kinds of edits fuzzy solve may apply where two parts join
+const (
+	EditMerge  = "merged"   // a letter ending one chunk and starting the next is written once
+	EditDrop   = "dropped"  // a letter at the end or the start of a chunk is left out
+	EditInsert = "inserted" // a letter is added between two chunks
+)
+
+// Edit is a change fuzzy solve applied to the chunks to get a word
+type Edit struct {
+	Kind   string
+	Letter rune
+	Join   int // the edit is where chunk Join-1 meets chunk Join
+	Part   int // chunk the letter is dropped from
+}
+
+// Explain tells what the edit did to the chunks, such as "merged p of cop and py"
+func (e Edit) Explain(chunks []string) string {
+	letter := string(e.Letter)
+	switch e.Kind {
+	case EditMerge:
+		return e.Kind + " " + letter + " of " + chunks[e.Join-1] + " and " + chunks[e.Join]
+	case EditInsert:
+		return e.Kind + " " + letter + " between " + chunks[e.Join-1] + " and " + chunks[e.Join]
+	default:
+		return e.Kind + " " + letter + " of " + chunks[e.Part]
+	}
+}
+
+// editedAt tells if there already is an edit at the join
+func editedAt(edits []Edit, join int) bool {
+	for _, e := range edits {
+		if e.Join == join {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fuzzyWalk walks the trie like walkSolutions but may spend up to budget edits,
+// at most one per join
+type fuzzyWalk struct {
+	ctx      context.Context
+	kubAssoc [][]string
+	budget   int
+	found    func(chunks []string, word string, edits []Edit)
+}
+
+func (w *fuzzyWalk) walk(n *dict.Node, chunks []string, word []rune, edits []Edit) {
+	if w.ctx.Err() != nil {
+		return
+	}
+	part := len(chunks)
+	if part == len(w.kubAssoc) {
+		if n.IsWord() && len(edits) > 0 {
+			w.found(append([]string{}, chunks...), string(word), append([]Edit{}, edits...))
+		}
+		return
+	}
+
+	canEdit := part > 0 && len(edits) < w.budget && !editedAt(edits, part)
+	for _, chunk := range w.kubAssoc[part] {
+		runes := []rune(chunk)
+		if len(runes) == 0 {
+			continue // an empty value has no letters to walk or edit
+		}
+		next := append(chunks, chunk)
+		w.walkChunk(n, next, word, runes, edits)
+		if !canEdit {
+			continue
+		}
+
+		if len(runes) > 1 {
+			kind := EditDrop
+			if runes[0] == word[len(word)-1] {
+				kind = EditMerge
+			}
+			w.walkChunk(n, next, word, runes[1:], append(edits, Edit{kind, runes[0], part, part}))
+		}
+		n.EachChild(func(r rune, child *dict.Node) {
+			w.walkChunk(child, next, append(word, r), runes, append(edits, Edit{EditInsert, r, part, part}))
+		})
+	}
+}
+
+// walkChunk walks the letters of the last chunk and goes on with the next part.
+// Unless it is the last part, the chunk may also lose its last letter
+func (w *fuzzyWalk) walkChunk(n *dict.Node, chunks []string, word []rune, runes []rune, edits []Edit) {
+	last := len(runes) - 1
+	if n = n.Walk(string(runes[:last])); n == nil {
+		return
+	}
+	word = append(word, runes[:last]...)
+
+	if next := n.Walk(string(runes[last])); next != nil {
+		w.walk(next, chunks, append(word, runes[last]), edits)
+	}
+
+	part := len(chunks) - 1
+	if last > 0 && part < len(w.kubAssoc)-1 && len(edits) < w.budget && !editedAt(edits, part+1) {
+		w.walk(n, chunks, word, append(edits, Edit{EditDrop, runes[last], part + 1, part}))
+	}
+}
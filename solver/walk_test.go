@@ -0,0 +1,64 @@
+package solver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ruslanbes/kubrai/dict"
+)
+
+func Test_walkSolutions(t *testing.T) {
+	trie := dict.NewTrie()
+	for _, w := range []string{"boycott", "boyscout", "copy", "ice cream", "x-ray"} {
+		trie.Insert(w)
+	}
+
+	tests := []struct {
+		name     string
+		kubAssoc [][]string
+		want     [][]string
+		want1    []string
+	}{
+		{
+			name:     "One",
+			kubAssoc: [][]string{{"man", "boy"}, {"sleep", "cot"}, {"t", "tea"}},
+			want:     [][]string{{"boy", "cot", "t"}},
+			want1:    []string{"boycott"},
+		},
+		{
+			name:     "Many",
+			kubAssoc: [][]string{{"cop", "boy"}, {"y", "scout"}},
+			want:     [][]string{{"cop", "y"}, {"boy", "scout"}},
+			want1:    []string{"copy", "boyscout"},
+		},
+		{
+			name:     "PrefixOnly",
+			kubAssoc: [][]string{{"boy"}, {"cot"}},
+			want:     [][]string{},
+			want1:    []string{},
+		},
+		{
+			name:     "Phrases",
+			kubAssoc: [][]string{{"ice", "x"}, {"cream", "ray"}},
+			want:     [][]string{{"ice", "cream"}, {"x", "ray"}},
+			want1:    []string{"ice cream", "x-ray"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := [][]string{}
+			got1 := []string{}
+			walkSolutions(context.Background(), trie.Root(), tt.kubAssoc, []string{}, "", func(comb []string, word string) {
+				got = append(got, comb)
+				got1 = append(got1, word)
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("walkSolutions() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("walkSolutions() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ruslanbes/kubrai/property"
+	"github.com/ruslanbes/kubrai/solver"
+)
+
+// assoc store backends, see propAssocStore
+const (
+	storeText = "text"
+	storeLog  = "log"
+)
+
+// assocLogLocation is where the log store keeps the associations of a playbook
+const assocLogLocation = "associations/associations.log"
+
+// log store records, keys and values are escaped as in association files
+const (
+	logSet    = '+' // +key:val,val sets the values of the key
+	logDelete = '-' // -key drops the key
+)
+
+// reasonBadRecord tells that a log line is neither a set nor a delete record
+const reasonBadRecord = "unknown record"
+
+// errUnknownStore tells that the AssocStore property names no backend
+var errUnknownStore = errors.New("unknown association store")
+
+// assocStore keeps the associations of a playbook. The solver can read it directly
+type assocStore interface {
+	solver.AssocStore
+	// Add adds the value to the key before its first longer value and returns the values of the key
+	Add(key, val string) ([]string, error)
+	// Remove removes the value from the key and returns the values left
+	Remove(key, val string) ([]string, error)
+	// Reverse lists the keys the value belongs to
+	Reverse(val string) []string
+	// Snapshot copies all the associations
+	Snapshot() map[string][]string
+}
+
+// assocStores keeps the stores opened by this run by their file
+var assocStores = map[string]assocStore{}
+
+func getFullAssocStoreLocation() string {
+	if property.AsString(propAssocStore) == storeLog {
+		return getCurrentPlaybookDir() + "/" + assocLogLocation
+	}
+
+	return getFullAssocFileLocation()
+}
+
+// openDefaultStore opens the association store of the current playbook once per run
+func openDefaultStore() (assocStore, error) {
+	if playbook := property.AsString(propPlaybookCurrent); !playbookExists(playbook) {
+		return nil, &playbookError{playbook}
+	}
+
+	file := getFullAssocStoreLocation()
+	if s, ok := assocStores[file]; ok {
+		return s, nil
+	}
+
+	var s assocStore
+	var err error
+	switch backend := property.AsString(propAssocStore); backend {
+	case storeText, "":
+		s, err = openTextStore(file)
+	case storeLog:
+		s, err = openLogStore(file, getFullAssocFileLocation())
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownStore, backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	assocStores[file] = s
+	return s, nil
+}
+
+// closeStores forgets the opened stores so that the next use reads the files again
+func closeStores() {
+	assocStores = map[string]assocStore{}
+}
+
+// saveDefaultAssoc replaces the associations of the current playbook
+func saveDefaultAssoc(assoc map[string][]string) error {
+	file := getFullAssocStoreLocation()
+	delete(assocStores, file)
+
+	if property.AsString(propAssocStore) == storeLog {
+		return writeAssocLog(file, assoc)
+	}
+	return saveAssoc(file, assoc)
+}
+
+// memStore keeps the associations in memory only
+type memStore struct {
+	assoc map[string][]string
+}
+
+func newMemStore(assoc map[string][]string) *memStore {
+	s := &memStore{assoc: make(map[string][]string, len(assoc))}
+	for k, vals := range assoc {
+		s.assoc[k] = append([]string{}, vals...)
+	}
+
+	return s
+}
+
+// Get gets the values of the key
+func (s *memStore) Get(key string) []string {
+	return append([]string{}, s.assoc[key]...)
+}
+
+// Keys lists the keys sorted
+func (s *memStore) Keys() []string {
+	return solver.MapStore(s.assoc).Keys()
+}
+
+// Add adds the value to the key before its first longer value
+func (s *memStore) Add(key, val string) ([]string, error) {
+	s.add(key, val)
+	return s.Get(key), nil
+}
+
+// Remove removes the value from the key, dropping the key without values
+func (s *memStore) Remove(key, val string) ([]string, error) {
+	s.remove(key, val)
+	return s.Get(key), nil
+}
+
+// Reverse lists the keys the value belongs to, sorted
+func (s *memStore) Reverse(val string) []string {
+	keys := []string{}
+	for k, vals := range s.assoc {
+		if findStringInSlice(val, vals) != -1 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Snapshot copies all the associations
+func (s *memStore) Snapshot() map[string][]string {
+	return newMemStore(s.assoc).assoc
+}
+
+// add tells if the value was new
+func (s *memStore) add(key, val string) bool {
+	if findStringInSlice(val, s.assoc[key]) != -1 {
+		return false
+	}
+
+	s.assoc[key] = addBeforeFirstLonger(val, s.Get(key))
+	return true
+}
+
+// remove tells if the value was there
+func (s *memStore) remove(key, val string) bool {
+	vals := s.Get(key)
+	if findStringInSlice(val, vals) == -1 {
+		return false
+	}
+
+	vals = removeByValue(val, vals)
+	if len(vals) == 0 {
+		delete(s.assoc, key)
+	} else {
+		s.assoc[key] = vals
+	}
+	return true
+}
+
+// textStore keeps the associations in the association file, rewriting it with a backup on every change
+type textStore struct {
+	*memStore
+	file string
+}
+
+func openTextStore(file string) (*textStore, error) {
+	assoc, err := readAssoc(file)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return &textStore{newMemStore(assoc), file}, nil
+}
+
+// Add adds the value to the key and saves the file if it changed
+func (s *textStore) Add(key, val string) ([]string, error) {
+	if s.add(key, val) {
+		if err := saveAssoc(s.file, s.assoc); err != nil {
+			return []string{}, err
+		}
+	}
+
+	return s.Get(key), nil
+}
+
+// Remove removes the value from the key and saves the file if it changed
+func (s *textStore) Remove(key, val string) ([]string, error) {
+	if s.remove(key, val) {
+		if err := saveAssoc(s.file, s.assoc); err != nil {
+			return []string{}, err
+		}
+	}
+
+	return s.Get(key), nil
+}
+
+// logStore keeps the associations in an append-only log. Every change appends the new values of the key,
+// the log is compacted on open once most of its records are outdated.
+// It keeps no backups, so undo needs the text store
+type logStore struct {
+	*memStore
+	file string
+}
+
+// openLogStore replays the log. Without a log yet, it starts the log from the association file seed if there is one
+func openLogStore(file, seed string) (*logStore, error) {
+	assoc, records, err := readAssocLog(file)
+	if os.IsNotExist(err) && seed != "" {
+		if assoc, err = seedAssocLog(file, seed); err != nil {
+			return nil, err
+		}
+	} else if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if records > 2*len(assoc) {
+		if err := writeAssocLog(file, assoc); err != nil {
+			return nil, err
+		}
+	}
+
+	return &logStore{newMemStore(assoc), file}, nil
+}
+
+// Add adds the value to the key and logs the change
+func (s *logStore) Add(key, val string) ([]string, error) {
+	if s.add(key, val) {
+		if err := s.log(key); err != nil {
+			return []string{}, err
+		}
+	}
+
+	return s.Get(key), nil
+}
+
+// Remove removes the value from the key and logs the change
+func (s *logStore) Remove(key, val string) ([]string, error) {
+	if s.remove(key, val) {
+		if err := s.log(key); err != nil {
+			return []string{}, err
+		}
+	}
+
+	return s.Get(key), nil
+}
+
+func (s *logStore) log(key string) error {
+	os.MkdirAll(filepath.Dir(s.file), 0777)
+	f, err := os.OpenFile(s.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString(logRecord(key, s.assoc[key]) + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// seedAssocLog writes the log of the associations in the association file, if there is such a file
+func seedAssocLog(file, seed string) (map[string][]string, error) {
+	assoc, err := readAssoc(seed)
+	if os.IsNotExist(err) {
+		return map[string][]string{}, nil
+	}
+	if err != nil {
+		return map[string][]string{}, err
+	}
+
+	return assoc, writeAssocLog(file, assoc)
+}
+
+func logRecord(key string, vals []string) string {
+	if len(vals) == 0 {
+		return string(logDelete) + escapeAssoc(key)
+	}
+
+	return string(logSet) + formatAssocLine(key, vals)
+}
+
+// readAssocLog replays the log and tells how many records it has
+func readAssocLog(file string) (map[string][]string, int, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return map[string][]string{}, 0, err
+	}
+	defer f.Close()
+
+	assoc := make(map[string][]string)
+
+	scanner := bufio.NewScanner(f)
+	line := 1
+	for ; scanner.Scan(); line++ {
+		record := scanner.Text()
+		switch {
+		case strings.HasPrefix(record, string(logDelete)):
+			key, ok := unescapeAssoc(record[1:])
+			if !ok {
+				return map[string][]string{}, 0, &assocLineError{file, line, record, reasonBadEscape}
+			}
+			delete(assoc, key)
+		case strings.HasPrefix(record, string(logSet)):
+			key, vals, reason := parseAssocLine(record[1:], true)
+			if reason != "" {
+				return map[string][]string{}, 0, &assocLineError{file, line, record, reason}
+			}
+			assoc[key] = vals
+		default:
+			return map[string][]string{}, 0, &assocLineError{file, line, record, reasonBadRecord}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return map[string][]string{}, 0, err
+	}
+
+	return assoc, line - 1, nil
+}
+
+// formatAssocLog writes the compact log of the associations: a record per key in sorted order
+func formatAssocLog(assoc map[string][]string) string {
+	var b strings.Builder
+	for _, k := range solver.MapStore(assoc).Keys() {
+		b.WriteString(logRecord(k, assoc[k]) + "\n")
+	}
+
+	return b.String()
+}
+
+// writeAssocLog writes the compact log, replacing the old log at once
+func writeAssocLog(file string, assoc map[string][]string) error {
+	os.MkdirAll(filepath.Dir(file), 0777)
+	tmpFile := file + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, []byte(formatAssocLog(assoc)), 0666); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, file)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ruslanbes/kubrai/fileutils"
+	"github.com/ruslanbes/kubrai/property"
+)
+
+func Test_assocStore(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+	})
+
+	textFile := "./test/data/associationsStore.txt"
+	logFile := "./test/data/associationsStore.log"
+	defer os.Remove(logFile)
+	defer func() {
+		os.Remove(textFile)
+		for i := 1; i <= maxBackups; i++ {
+			os.Remove(backupName(textFile, i))
+		}
+	}()
+
+	tests := []struct {
+		name   string
+		open   func() (assocStore, error)
+		reopen bool
+	}{
+		{
+			name: "Memory",
+			open: func() (assocStore, error) { return newMemStore(map[string][]string{}), nil },
+		},
+		{
+			name:   "Text",
+			open:   func() (assocStore, error) { return openTextStore(textFile) },
+			reopen: true,
+		},
+		{
+			name:   "Log",
+			open:   func() (assocStore, error) { return openLogStore(logFile, "") },
+			reopen: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.open()
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+
+			s.Add("policeman", "bobby")
+			s.Add("policeman", "cop")
+			s.Add("policeman", "cop")
+			s.Add("why", "y")
+			s.Add("kid", "boy")
+			s.Add("girl", "boy")
+			if got, err := s.Remove("kid", "boy"); err != nil || !reflect.DeepEqual(got, []string{}) {
+				t.Errorf("Remove() = %v, %v, want %v", got, err, []string{})
+			}
+
+			want := map[string][]string{"girl": {"boy"}, "policeman": {"cop", "bobby"}, "why": {"y"}}
+			if tt.reopen {
+				if s, err = tt.open(); err != nil {
+					t.Fatalf("reopen: %v", err)
+				}
+			}
+			if got := s.Snapshot(); !reflect.DeepEqual(got, want) {
+				t.Errorf("Snapshot() = %v, want %v", got, want)
+			}
+			if got := s.Get("policeman"); !reflect.DeepEqual(got, []string{"cop", "bobby"}) {
+				t.Errorf("Get() = %v, want %v", got, []string{"cop", "bobby"})
+			}
+			if got := s.Keys(); !reflect.DeepEqual(got, []string{"girl", "policeman", "why"}) {
+				t.Errorf("Keys() = %v, want %v", got, []string{"girl", "policeman", "why"})
+			}
+			if got := s.Reverse("boy"); !reflect.DeepEqual(got, []string{"girl"}) {
+				t.Errorf("Reverse() = %v, want %v", got, []string{"girl"})
+			}
+		})
+	}
+}
+
+func Test_openLogStore(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+	})
+
+	logFile := "./test/data/associationsStore.log"
+	defer os.Remove(logFile)
+
+	t.Run("Compacts", func(t *testing.T) {
+		fileutils.FilePutContents(logFile, strings.Join([]string{
+			"+policeman:bobby",
+			"+policeman:cop,bobby",
+			"+kid:boy",
+			"-kid",
+			"",
+		}, "\n"))
+
+		s, err := openLogStore(logFile, "")
+		if err != nil {
+			t.Fatalf("openLogStore() error = %v", err)
+		}
+		if got := s.Get("policeman"); !reflect.DeepEqual(got, []string{"cop", "bobby"}) {
+			t.Errorf("Get() = %v, want %v", got, []string{"cop", "bobby"})
+		}
+		if got, _ := ioutil.ReadFile(logFile); string(got) != "+policeman:cop,bobby\n" {
+			t.Errorf("openLogStore() left the log %q, want %q", got, "+policeman:cop,bobby\n")
+		}
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		fileutils.FilePutContents(logFile, "+policeman:cop\npoliceman:bobby\n")
+
+		_, err := openLogStore(logFile, "")
+		want := &assocLineError{logFile, 2, "policeman:bobby", reasonBadRecord}
+		if !reflect.DeepEqual(err, want) {
+			t.Errorf("openLogStore() error = %v, want %v", err, want)
+		}
+	})
+}
+
+func Test_runAdd_logStore(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propAssocStore:            storeLog,
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+	})
+	defer os.Remove(getFullAssocStoreLocation())
+
+	saveDefaultAssoc(map[string][]string{"policeman": {"cop"}})
+	if got, err := runAdd("policeman", "bobby"); err != nil || !reflect.DeepEqual(got, []string{"cop", "bobby"}) {
+		t.Errorf("runAdd() = %v, %v, want %v", got, err, []string{"cop", "bobby"})
+	}
+
+	closeStores()
+	if got, err := runView("policeman"); err != nil || !reflect.DeepEqual(got, []string{"cop", "bobby"}) {
+		t.Errorf("runView() = %v, %v, want %v", got, err, []string{"cop", "bobby"})
+	}
+	if got := runUndoCommand([]string{}).text(); got != "501 NOT IMPLEMENTED\nundo" {
+		t.Errorf("runUndoCommand() = %q, want %q", got, "501 NOT IMPLEMENTED\nundo")
+	}
+}
+
+func Test_openDefaultStore_seedsLog(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+	})
+	saveDefaultAssoc(map[string][]string{"policeman": {"cop"}})
+
+	property.SetProperties(map[string]string{propAssocStore: storeLog})
+	logFile := getFullAssocStoreLocation()
+	os.Remove(logFile)
+	defer os.Remove(logFile)
+
+	if got, err := runView("policeman"); err != nil || !reflect.DeepEqual(got, []string{"cop"}) {
+		t.Errorf("runView() = %v, %v, want %v", got, err, []string{"cop"})
+	}
+	if got, _ := ioutil.ReadFile(logFile); string(got) != "+policeman:cop\n" {
+		t.Errorf("openDefaultStore() started the log %q, want %q", got, "+policeman:cop\n")
+	}
+}
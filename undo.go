@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ruslanbes/kubrai/property"
+)
+
+const undoList = "list"
+
+func runUndoCommand(args []string) response {
+	// only the text store keeps backups
+	if property.AsString(propAssocStore) == storeLog {
+		return statusResponse(statusNotImplemented, vUndo)
+	}
+
+	if len(args) > 0 && args[0] == undoList {
+		res, err := runUndoList()
+		if err != nil {
+			return errorResponse(err)
+		}
+		if len(res) == 0 {
+			return statusResponse(statusNotFound)
+		}
+		lines := make([]string, len(res))
+		for i, b := range res {
+			lines[i] = b.line()
+		}
+		r := newResponse(lines)
+		r.Results = res
+		return r
+	}
+
+	n := 1
+	if len(args) > 0 {
+		num, err := strconv.Atoi(args[0])
+		if err != nil || num < 1 || num > maxBackups {
+			return statusResponse(statusBadRequest)
+		}
+		n = num
+	}
+
+	res, err := runUndo(n)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	r := newResponse([]string{"undone: " + strings.Join(res, " ")})
+	if len(res) == 0 {
+		r.lines = []string{"undone: no changes"}
+	}
+	r.Results = res
+	return r
+}
+
+// runUndo restores the association file to its state before the last n commands that saved it
+// and returns what that changed
+func runUndo(n int) ([]string, error) {
+	assocFile := getFullAssocFileLocation()
+	bak, err := readAssoc(backupName(assocFile, n))
+	if err != nil {
+		return []string{}, err
+	}
+	assoc, err := readAssoc(assocFile)
+	if err != nil {
+		return []string{}, err
+	}
+
+	if err := restoreBackup(assocFile, n); err != nil {
+		return []string{}, err
+	}
+	return diffAssoc(bak, assoc), nil
+}
+
+// undoBackup tells what restoring backup n would change, see diffAssoc
+type undoBackup struct {
+	Backup  int      `json:"backup"`
+	Changes []string `json:"changes"`
+}
+
+func (b undoBackup) line() string {
+	changes := "no changes"
+	if len(b.Changes) > 0 {
+		changes = strings.Join(b.Changes, " ")
+	}
+
+	return strconv.Itoa(b.Backup) + ": " + changes
+}
+
+// runUndoList tells for every backup what restoring it would change
+func runUndoList() ([]undoBackup, error) {
+	assocFile := getFullAssocFileLocation()
+	assoc, err := readAssoc(assocFile)
+	if err != nil {
+		return []undoBackup{}, err
+	}
+
+	res := []undoBackup{}
+	for i := 1; i <= maxBackups; i++ {
+		bakFile := backupName(assocFile, i)
+		if _, err := os.Stat(bakFile); err != nil {
+			break
+		}
+
+		bak, err := readAssoc(bakFile)
+		if err != nil {
+			return []undoBackup{}, err
+		}
+		res = append(res, undoBackup{i, diffAssoc(bak, assoc)})
+	}
+
+	return res, nil
+}
+
+// restoreBackup puts backup n in place of the file and drops the newer backups
+func restoreBackup(file string, n int) error {
+	if err := os.Rename(backupName(file, n), file); err != nil {
+		return err
+	}
+
+	for i := 1; i < n; i++ {
+		os.Remove(backupName(file, i))
+	}
+	for i := n + 1; i <= maxBackups; i++ {
+		os.Rename(backupName(file, i), backupName(file, i-n))
+	}
+
+	delete(assocStores, file)
+	return nil
+}
+
+// diffAssoc lists the pairs to add (+) and to remove (-) to turn cur into target
+func diffAssoc(target, cur map[string][]string) []string {
+	res := []string{}
+	for k, vals := range target {
+		for _, v := range vals {
+			if findStringInSlice(v, cur[k]) == -1 {
+				res = append(res, "+"+buildAssocString(k, []string{v}))
+			}
+		}
+	}
+	for k, vals := range cur {
+		for _, v := range vals {
+			if findStringInSlice(v, target[k]) == -1 {
+				res = append(res, "-"+buildAssocString(k, []string{v}))
+			}
+		}
+	}
+
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_runUndo(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+	})
+
+	os.RemoveAll(getCurrentPlaybookDir() + "/associations")
+	saveDefaultAssoc(map[string][]string{"boy": {"girl"}})
+	runAdd("boy", "man")
+	runAdd("girl", "woman")
+	runRemove("boy", "girl")
+
+	wantList := []undoBackup{
+		{1, []string{"+boy:girl"}},
+		{2, []string{"+boy:girl", "-girl:woman"}},
+		{3, []string{"+boy:girl", "-boy:man", "-girl:woman"}},
+	}
+	if got, err := runUndoList(); err != nil || !reflect.DeepEqual(got, wantList) {
+		t.Errorf("runUndoList() = %v, %v, want %v", got, err, wantList)
+	}
+
+	tests := []struct {
+		name  string
+		n     int
+		want  []string
+		want1 bool
+		view  map[string][]string
+	}{
+		{
+			name:  "Last",
+			n:     1,
+			want:  []string{"+boy:girl"},
+			want1: true,
+			view:  map[string][]string{"boy": {"man", "girl"}, "girl": {"woman"}},
+		},
+		{
+			name:  "Two",
+			n:     2,
+			want:  []string{"-boy:man", "-girl:woman"},
+			want1: true,
+			view:  map[string][]string{"boy": {"girl"}, "girl": {}},
+		},
+		{
+			name:  "NoMoreBackups",
+			n:     2,
+			want:  []string{},
+			want1: false,
+			view:  map[string][]string{"boy": {"girl"}, "girl": {}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runUndo(tt.n)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runUndo() got = %v, want %v", got, tt.want)
+			}
+			if got1 := err == nil; got1 != tt.want1 {
+				t.Errorf("runUndo() got1 = %v, want %v", got1, tt.want1)
+			}
+			for k, v := range tt.view {
+				if got, err := runView(k); err != nil || !reflect.DeepEqual(got, v) {
+					t.Errorf("runView(%v) = %v, %v, want %v", k, got, err, v)
+				}
+			}
+		})
+	}
+}
+
+func Test_runUndoCommand_wholeCommand(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAddAutoBothMaxlen:     "5",
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+		propPlaybookCurrent:       "default",
+		propPlaybooksDir:          "./test/data/playbooks",
+	})
+
+	os.RemoveAll(getCurrentPlaybookDir() + "/associations")
+	saveDefaultAssoc(map[string][]string{"boy": {"girl"}})
+
+	// a short key is added both ways, which saves twice
+	runVerb(vAdd, []string{"why", "y"})
+	want := "undone: -why:y -y:why"
+	if got := runVerb(vUndo, []string{}).text(); got != want {
+		t.Errorf("runVerb(undo) = %v, want %v", got, want)
+	}
+	for _, k := range []string{"why", "y"} {
+		if got, err := runView(k); err != nil || !reflect.DeepEqual(got, []string{}) {
+			t.Errorf("runView(%v) = %v, %v, want %v", k, got, err, []string{})
+		}
+	}
+}
+
+func Test_diffAssoc(t *testing.T) {
+	setUpTestProperties(map[string]string{
+		propAssocFileKeySeparator: ":",
+		propAssocFileValSeparator: ",",
+	})
+
+	type args struct {
+		target map[string][]string
+		cur    map[string][]string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "Same",
+			args: args{map[string][]string{"a": {"b"}}, map[string][]string{"a": {"b"}}},
+			want: []string{},
+		},
+		{
+			name: "AddAndRemove",
+			args: args{map[string][]string{"a": {"b", "c"}}, map[string][]string{"a": {"b"}, "d": {"e"}}},
+			want: []string{"+a:c", "-d:e"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffAssoc(tt.args.target, tt.args.cur); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffAssoc() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}